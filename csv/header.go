@@ -0,0 +1,142 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mithrandie/go-text"
+)
+
+type Header struct {
+	names []string
+	index map[string]int
+}
+
+func NewHeader(names []string) *Header {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+
+	return &Header{
+		names: names,
+		index: index,
+	}
+}
+
+func (h *Header) Index(name string) int {
+	if i, ok := h.index[name]; ok {
+		return i
+	}
+	return -1
+}
+
+// Indices returns the indices of the names, which can be used as Reader.Columns.
+func (h *Header) Indices(names ...string) ([]int, error) {
+	indices := make([]int, len(names))
+	for i, name := range names {
+		if indices[i] = h.Index(name); indices[i] < 0 {
+			return nil, errors.New(fmt.Sprintf("unknown column: %q", name))
+		}
+	}
+	return indices, nil
+}
+
+func (h *Header) Len() int {
+	return len(h.names)
+}
+
+func (h *Header) Fields() []string {
+	return h.names
+}
+
+// HeaderNormalizer makes header names unique and non-blank.
+//
+// BlankName is a format with a column number to name blank headers, such as "column%d".
+// DuplicateName is a format with a name and a sequence number starting from 2 to rename
+// duplicated headers, such as "%s_%d".
+type HeaderNormalizer struct {
+	TrimSpace     bool
+	BlankName     string
+	DuplicateName string
+}
+
+func NewHeaderNormalizer() HeaderNormalizer {
+	return HeaderNormalizer{
+		TrimSpace:     true,
+		BlankName:     "column%d",
+		DuplicateName: "%s_%d",
+	}
+}
+
+func (n HeaderNormalizer) Normalize(names []string) []string {
+	normalized := make([]string, len(names))
+	used := make(map[string]bool, len(names))
+
+	for i, name := range names {
+		if n.TrimSpace {
+			name = strings.TrimSpace(name)
+		}
+		if len(name) < 1 {
+			name = fmt.Sprintf(n.BlankName, i+1)
+		}
+		normalized[i] = name
+	}
+
+	duplicated := make([]bool, len(names))
+	for i, name := range normalized {
+		if used[name] {
+			duplicated[i] = true
+		} else {
+			used[name] = true
+		}
+	}
+
+	for i, name := range normalized {
+		if !duplicated[i] {
+			continue
+		}
+		for seq := 2; ; seq++ {
+			renamed := fmt.Sprintf(n.DuplicateName, name, seq)
+			if !used[renamed] {
+				normalized[i] = renamed
+				used[renamed] = true
+				break
+			}
+		}
+	}
+
+	return normalized
+}
+
+type NamedRecord struct {
+	Header *Header
+	Values []text.RawText
+}
+
+// Get returns the value of the column. The second value is false if the column does not exist in the header.
+func (r NamedRecord) Get(name string) (text.RawText, bool) {
+	i := r.Header.Index(name)
+	if i < 0 {
+		return nil, false
+	}
+	if len(r.Values) <= i {
+		return nil, true
+	}
+	return r.Values[i], true
+}
+
+func (r NamedRecord) Map() map[string]text.RawText {
+	m := make(map[string]text.RawText, r.Header.Len())
+	for i, name := range r.Header.Fields() {
+		if i < len(r.Values) {
+			m[name] = r.Values[i]
+		} else {
+			m[name] = nil
+		}
+	}
+	return m
+}
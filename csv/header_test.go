@@ -0,0 +1,96 @@
+package csv
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+var headerNormalizerNormalizeTests = []struct {
+	Name          string
+	TrimSpace     bool
+	DuplicateName string
+	Input         []string
+	Expect        []string
+}{
+	{
+		Name:          "Unique Names",
+		TrimSpace:     true,
+		DuplicateName: "%s_%d",
+		Input:         []string{"a", "b", "c"},
+		Expect:        []string{"a", "b", "c"},
+	},
+	{
+		Name:          "Duplicate and Blank Names",
+		TrimSpace:     true,
+		DuplicateName: "%s_%d",
+		Input:         []string{"a", " a ", "", "a_2", "a", " "},
+		Expect:        []string{"a", "a_3", "column3", "a_2", "a_4", "column6"},
+	},
+	{
+		Name:          "Without Trimming",
+		TrimSpace:     false,
+		DuplicateName: "%s(%d)",
+		Input:         []string{"a", " a", "a"},
+		Expect:        []string{"a", " a", "a(2)"},
+	},
+}
+
+func TestHeaderNormalizer_Normalize(t *testing.T) {
+	for _, v := range headerNormalizerNormalizeTests {
+		n := NewHeaderNormalizer()
+		n.TrimSpace = v.TrimSpace
+		n.DuplicateName = v.DuplicateName
+
+		result := n.Normalize(v.Input)
+		if !reflect.DeepEqual(result, v.Expect) {
+			t.Errorf("%s: result = %q, want %q", v.Name, result, v.Expect)
+		}
+	}
+}
+
+func TestNamedRecord_Get(t *testing.T) {
+	record := NamedRecord{
+		Header: NewHeader([]string{"a", "b", "c"}),
+		Values: []text.RawText{text.RawText("1"), nil},
+	}
+
+	if v, ok := record.Get("a"); !ok || !reflect.DeepEqual(v, text.RawText("1")) {
+		t.Errorf("value = %q, %t, want %q, %t", v, ok, "1", true)
+	}
+	if v, ok := record.Get("c"); !ok || v != nil {
+		t.Errorf("value = %q, %t, want %v, %t", v, ok, nil, true)
+	}
+	if v, ok := record.Get("d"); ok || v != nil {
+		t.Errorf("value = %q, %t, want %v, %t", v, ok, nil, false)
+	}
+
+	expect := map[string]text.RawText{
+		"a": text.RawText("1"),
+		"b": nil,
+		"c": nil,
+	}
+	if m := record.Map(); !reflect.DeepEqual(m, expect) {
+		t.Errorf("map = %q, want %q", m, expect)
+	}
+}
+
+func TestHeader_Indices(t *testing.T) {
+	h := NewHeader([]string{"a", "b", "c"})
+
+	indices, err := h.Indices("c", "a")
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(indices, []int{2, 0}) {
+		t.Errorf("indices = %v, want %v", indices, []int{2, 0})
+	}
+
+	expectErr := "unknown column: \"d\""
+	if _, err = h.Indices("a", "d"); err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
@@ -0,0 +1,65 @@
+package csv
+
+import (
+	"io"
+)
+
+type MapReader struct {
+	Normalizer HeaderNormalizer
+
+	reader *Reader
+
+	Header *Header
+}
+
+func NewMapReader(r *Reader) *MapReader {
+	return &MapReader{
+		Normalizer: NewHeaderNormalizer(),
+		reader:     r,
+	}
+}
+
+func (r *MapReader) ReadHeader() (*Header, error) {
+	names, err := r.reader.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Header = NewHeader(r.Normalizer.Normalize(names))
+	return r.Header, nil
+}
+
+func (r *MapReader) Read() (NamedRecord, error) {
+	if r.Header == nil {
+		if _, err := r.ReadHeader(); err != nil {
+			return NamedRecord{}, err
+		}
+	}
+
+	values, err := r.reader.Read()
+	if err != nil {
+		return NamedRecord{}, err
+	}
+
+	return NamedRecord{
+		Header: r.Header,
+		Values: values,
+	}, nil
+}
+
+func (r *MapReader) ReadAll() ([]NamedRecord, error) {
+	records := make([]NamedRecord, 0, 160)
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
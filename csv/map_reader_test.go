@@ -0,0 +1,50 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+func TestMapReader_ReadAll(t *testing.T) {
+	input := "id,name,name,\n1,a,b,c\n2,d,e,f"
+	expectHeader := []string{"id", "name", "name_2", "column4"}
+	expect := []map[string]text.RawText{
+		{"id": text.RawText("1"), "name": text.RawText("a"), "name_2": text.RawText("b"), "column4": text.RawText("c")},
+		{"id": text.RawText("2"), "name": text.RawText("d"), "name_2": text.RawText("e"), "column4": text.RawText("f")},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	mr := NewMapReader(r)
+
+	records, err := mr.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	if !reflect.DeepEqual(mr.Header.Fields(), expectHeader) {
+		t.Errorf("header = %q, want %q", mr.Header.Fields(), expectHeader)
+	}
+
+	result := make([]map[string]text.RawText, 0, len(records))
+	for _, record := range records {
+		result = append(result, record.Map())
+	}
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("records = %q, want %q", result, expect)
+	}
+
+	input = "id,\"name\n"
+	expectErr := "line 2, column 1: extraneous \" in field"
+
+	r, _ = NewReader(strings.NewReader(input), text.UTF8)
+	mr = NewMapReader(r)
+	_, err = mr.ReadAll()
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
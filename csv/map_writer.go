@@ -0,0 +1,61 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+)
+
+type MapWriter struct {
+	QuoteHeader bool
+
+	writer  *Writer
+	columns []string
+	index   map[string]int
+	record  []Field
+}
+
+func NewMapWriter(w *Writer, columns []string) (*MapWriter, error) {
+	index := make(map[string]int, len(columns))
+	for i, name := range columns {
+		if _, ok := index[name]; ok {
+			return nil, errors.New(fmt.Sprintf("duplicate column: %q", name))
+		}
+		index[name] = i
+	}
+
+	return &MapWriter{
+		QuoteHeader: false,
+		writer:      w,
+		columns:     columns,
+		index:       index,
+		record:      make([]Field, len(columns)),
+	}, nil
+}
+
+func (w *MapWriter) WriteHeader() error {
+	for i, name := range w.columns {
+		w.record[i] = NewField(name, w.QuoteHeader)
+	}
+	return w.writer.Write(w.record)
+}
+
+// Write writes the fields in the column order. Columns that do not exist in the record are written as empty fields.
+func (w *MapWriter) Write(record map[string]Field) error {
+	for i := range w.record {
+		w.record[i] = Field{}
+	}
+
+	for name, field := range record {
+		i, ok := w.index[name]
+		if !ok {
+			return errors.New(fmt.Sprintf("unknown column: %q", name))
+		}
+		w.record[i] = field
+	}
+
+	return w.writer.Write(w.record)
+}
+
+func (w *MapWriter) Flush() error {
+	return w.writer.Flush()
+}
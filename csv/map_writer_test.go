@@ -0,0 +1,58 @@
+package csv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+func TestMapWriter_Write(t *testing.T) {
+	records := []map[string]Field{
+		{"id": NewField("1", false), "name": NewField("a,b", false)},
+		{"name": NewField("c", true), "note": NewField("d", false)},
+	}
+	expect := "\"id\",\"name\",\"note\"\n" +
+		"1,\"a,b\",\n" +
+		",\"c\",d"
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, text.LF, text.UTF8)
+	mw, err := NewMapWriter(w, []string{"id", "name", "note"})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	mw.QuoteHeader = true
+
+	if err = mw.WriteHeader(); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	for _, record := range records {
+		if err = mw.Write(record); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+	}
+	if err = mw.Flush(); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+
+	expectErr := "unknown column: \"memo\""
+	err = mw.Write(map[string]Field{"memo": NewField("e", false)})
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+
+	expectErr = "duplicate column: \"id\""
+	_, err = NewMapWriter(w, []string{"id", "id"})
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
@@ -0,0 +1,97 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/mithrandie/go-text/internal/structs"
+)
+
+// Unmarshal reads the header and all records from r, and stores them into the slice pointed to by v.
+// Struct fields are mapped to the columns by the names specified in the "text" tag or the field names.
+func Unmarshal(r *Reader, v interface{}) error {
+	slice, t, err := structs.SliceOf(v)
+	if err != nil {
+		return err
+	}
+	fields, err := structs.Fields(t)
+	if err != nil {
+		return err
+	}
+
+	header, err := r.ReadHeader()
+	if err != nil {
+		return err
+	}
+	h := NewHeader(header)
+
+	columns := make([]int, len(fields))
+	for i, f := range fields {
+		columns[i] = h.Index(f.Name)
+	}
+
+	for {
+		record, err := r.parseRecord(r.WithoutNull, false, false)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		elem := structs.AppendElem(slice)
+		for i, f := range fields {
+			if columns[i] < 0 || len(record) <= columns[i] {
+				continue
+			}
+			if err = structs.Decode(record[columns[i]], elem.FieldByIndex(f.Index), f.Format); err != nil {
+				return errors.New(fmt.Sprintf("record %d, field %s: %s", r.record-1, f.Name, err.Error()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Marshal writes the header and the elements of the slice v to w.
+// Fields with the "quote" option in the "text" tag are enclosed in quotation marks.
+func Marshal(w *Writer, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	t, err := structs.StructOf(v)
+	if err != nil {
+		return err
+	}
+	fields, err := structs.Fields(t)
+	if err != nil {
+		return err
+	}
+
+	record := make([]Field, len(fields))
+	for i, f := range fields {
+		record[i] = NewField(f.Name, f.Quote)
+	}
+	if err = w.Write(record); err != nil {
+		return err
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		elem, ok := structs.Elem(rv, i)
+		for j, f := range fields {
+			s, isNull := "", false
+			if ok {
+				if s, isNull, err = structs.Encode(elem.FieldByIndex(f.Index), f.Format); err != nil {
+					return errors.New(fmt.Sprintf("field %s: %s", f.Name, err.Error()))
+				}
+			}
+			record[j] = NewField(s, f.Quote)
+			record[j].Null = isNull
+		}
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package csv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mithrandie/go-text"
+)
+
+type marshalTestRecord struct {
+	ID      int       `text:"id"`
+	Name    string    `text:"name,quote"`
+	Score   *float64  `text:"score"`
+	Updated time.Time `text:"updated,format=2006-01-02"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := "name,id,score,updated,memo\n\"abc\",1,1.5,2012-02-03,x\n\"d,e\",2,,2012-02-04,y"
+	score := 1.5
+	expect := []marshalTestRecord{
+		{ID: 1, Name: "abc", Score: &score, Updated: time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "d,e", Score: nil, Updated: time.Date(2012, 2, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	var records []marshalTestRecord
+	if err := Unmarshal(r, &records); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(records, expect) {
+		t.Errorf("records = %v, want %v", records, expect)
+	}
+
+	input = "id,name\n1,abc\nx,def"
+	expectErr := "record 2, field id: strconv.ParseInt: parsing \"x\": invalid syntax"
+
+	r, _ = NewReader(strings.NewReader(input), text.UTF8)
+	var ptrRecords []*marshalTestRecord
+	err := Unmarshal(r, &ptrRecords)
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+
+	expectErr = "non-nil pointer to a slice of structs is required, got []csv.marshalTestRecord"
+	err = Unmarshal(r, records)
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	score := 1.5
+	records := []*marshalTestRecord{
+		{ID: 1, Name: "abc", Score: &score, Updated: time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC)},
+		nil,
+		{ID: 2, Name: "d\"e", Score: nil, Updated: time.Date(2012, 2, 4, 0, 0, 0, 0, time.UTC)},
+	}
+	expect := "id,\"name\",score,updated\n" +
+		"1,\"abc\",1.5,2012-02-03\n" +
+		",\"\",,\n" +
+		"2,\"d\"\"e\",,2012-02-04"
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, text.LF, text.UTF8)
+	if err := Marshal(w, records); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+}
@@ -0,0 +1,588 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/mithrandie/go-text"
+)
+
+const DefaultChunkSize = 4 * 1024 * 1024
+
+type scanState int
+
+const (
+	stateRecordStart scanState = iota
+	stateFieldStart
+	stateUnquoted
+	stateQuoted
+	stateQuotedEscaped
+)
+
+const scanStates = 5
+
+type chunkScan struct {
+	endState   [scanStates]scanState
+	boundary   [scanStates]int64
+	lines      [scanStates]int
+	totalLines int
+}
+
+type segment struct {
+	start int64
+	end   int64
+	line  int
+}
+
+type segmentResult struct {
+	records [][]text.RawText
+	// lines and offsets are the positions where the records start.
+	lines           []int
+	offsets         []int64
+	parsed          int
+	errors          []*ParseError
+	fieldsPerRecord int
+	lineBreak       text.LineBreak
+	enclosedAll     bool
+	err             error
+}
+
+// ParallelReader reads CSV from an io.ReaderAt by splitting the input into chunks
+// and parsing them concurrently. Records are returned in the original order.
+//
+// Only UTF-8 and Shift-JIS encoded inputs with an ASCII delimiter are supported.
+type ParallelReader struct {
+	Delimiter         rune
+	WithoutNull       bool
+	AllowUnevenFields bool
+	LazyQuotes        bool
+	Recovery          RecoveryMode
+	Encoding          text.Encoding
+
+	Workers   int
+	ChunkSize int
+
+	// Limits restricts resources consumed by hostile input.
+	// MaxRecords counts every record read including the header.
+	Limits text.Limits
+
+	// StripFormulaEscape removes the escape character prepended by FormulaSanitizer.
+	StripFormulaEscape bool
+
+	reader   io.ReaderAt
+	size     int64
+	offset   int64
+	line     int
+	record   int
+	returned int
+
+	results chan chan *segmentResult
+	done    chan struct{}
+	current *segmentResult
+	pos     int
+	err     error
+
+	FieldsPerRecord int
+
+	DetectedLineBreak text.LineBreak
+	EnclosedAll       bool
+
+	Errors []*ParseError
+}
+
+func NewParallelReader(r io.ReaderAt, size int64, enc text.Encoding) (*ParallelReader, error) {
+	switch enc {
+	case text.UTF8, text.UTF8M, text.SJIS:
+	default:
+		return nil, errors.New(fmt.Sprintf("parallel reading is not supported in %s", enc))
+	}
+
+	return &ParallelReader{
+		Delimiter:         ',',
+		WithoutNull:       false,
+		AllowUnevenFields: false,
+		LazyQuotes:        false,
+		Recovery:          NoRecovery,
+		Encoding:          enc,
+		Workers:           runtime.NumCPU(),
+		ChunkSize:         DefaultChunkSize,
+		reader:            r,
+		size:              size,
+		offset:            0,
+		line:              1,
+		FieldsPerRecord:   0,
+		EnclosedAll:       true,
+	}, nil
+}
+
+func (r *ParallelReader) ReadHeader() ([]string, error) {
+	if r.results != nil {
+		return nil, errors.New("header must be read before records")
+	}
+	if err := r.validateDelimiter(); err != nil {
+		return nil, err
+	}
+
+	end, lines, err := r.firstRecordEnd()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := r.newReader(segment{start: r.offset, end: end, line: r.line}, r.FieldsPerRecord)
+	if err != nil {
+		return nil, err
+	}
+	header, err := reader.ReadHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	r.offset = end
+	r.line = r.line + lines
+	r.record = reader.record
+	r.returned = 1
+	r.FieldsPerRecord = reader.FieldsPerRecord
+	r.DetectedLineBreak = reader.DetectedLineBreak
+	r.EnclosedAll = reader.EnclosedAll
+	return header, nil
+}
+
+func (r *ParallelReader) Read() ([]text.RawText, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if r.results == nil {
+		if err := r.start(); err != nil {
+			r.err = err
+			return nil, err
+		}
+	}
+
+	for r.current == nil || len(r.current.records) <= r.pos {
+		if r.current != nil && r.current.err != nil {
+			r.err = r.current.err
+			_ = r.Close()
+			return nil, r.err
+		}
+
+		future, ok := <-r.results
+		if !ok {
+			r.err = io.EOF
+			return nil, io.EOF
+		}
+		r.merge(<-future)
+	}
+
+	record := r.current.records[r.pos]
+	r.pos++
+	if r.Limits.RecordsExceeded(r.returned + 1) {
+		lerr := r.Limits.NewLimitError(text.RecordsLimit, r.current.lines[r.pos-1])
+		r.err = &ParseError{
+			Kind:    text.LimitExceededError,
+			Line:    lerr.Line,
+			Offset:  r.current.offsets[r.pos-1],
+			Record:  r.returned,
+			Message: lerr.Message(),
+			Err:     lerr,
+		}
+		_ = r.Close()
+		return nil, r.err
+	}
+	r.returned++
+	return record, nil
+}
+
+func (r *ParallelReader) ReadAll() ([][]text.RawText, error) {
+	records := make([][]text.RawText, 0, 160)
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// Close stops the parsing goroutines. It must be called if the reader is abandoned before reaching the end.
+func (r *ParallelReader) Close() error {
+	if r.done != nil {
+		select {
+		case <-r.done:
+		default:
+			close(r.done)
+		}
+	}
+	return nil
+}
+
+func (r *ParallelReader) merge(res *segmentResult) {
+	base := r.record
+	r.record = r.record + res.parsed
+
+	for _, e := range res.errors {
+		perr := *e
+		perr.Record = perr.Record + base
+		r.Errors = append(r.Errors, &perr)
+	}
+
+	if perr, ok := res.err.(*ParseError); ok {
+		e := *perr
+		e.Record = e.Record + base
+		res.err = &e
+	}
+
+	if r.FieldsPerRecord < res.fieldsPerRecord {
+		r.FieldsPerRecord = res.fieldsPerRecord
+	}
+	if r.DetectedLineBreak == "" {
+		r.DetectedLineBreak = res.lineBreak
+	}
+	if !res.enclosedAll {
+		r.EnclosedAll = false
+	}
+
+	r.current = res
+	r.pos = 0
+}
+
+func (r *ParallelReader) validateDelimiter() error {
+	limit := rune(0x80)
+	if r.Encoding == text.SJIS {
+		limit = 0x40
+	}
+	if limit <= r.Delimiter || r.Delimiter == '"' || r.Delimiter == '\r' || r.Delimiter == '\n' {
+		return errors.New(fmt.Sprintf("delimiter %q is not supported in parallel reading", r.Delimiter))
+	}
+	return nil
+}
+
+func (r *ParallelReader) start() error {
+	if err := r.validateDelimiter(); err != nil {
+		return err
+	}
+
+	if r.Workers < 1 {
+		r.Workers = 1
+	}
+	if r.ChunkSize < 1 {
+		r.ChunkSize = DefaultChunkSize
+	}
+
+	segments, err := r.splitSegments()
+	if err != nil {
+		return err
+	}
+
+	fieldsPerRecord := r.FieldsPerRecord
+	if fieldsPerRecord < 1 && !r.AllowUnevenFields && 0 < len(segments) {
+		reader, err := r.newReader(segments[0], 0)
+		if err != nil {
+			return err
+		}
+		_, _ = reader.Read()
+		fieldsPerRecord = reader.FieldsPerRecord
+	}
+
+	r.results = make(chan chan *segmentResult, r.Workers)
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.results)
+
+		for _, seg := range segments {
+			future := make(chan *segmentResult, 1)
+			select {
+			case r.results <- future:
+			case <-r.done:
+				return
+			}
+
+			go func(seg segment) {
+				future <- r.parseSegment(seg, fieldsPerRecord)
+			}(seg)
+		}
+	}()
+
+	return nil
+}
+
+func (r *ParallelReader) newReader(seg segment, fieldsPerRecord int) (*Reader, error) {
+	enc := r.Encoding
+	if enc == text.UTF8M && 0 < seg.start {
+		enc = text.UTF8
+	}
+
+	reader, err := NewReader(io.NewSectionReader(r.reader, seg.start, seg.end-seg.start), enc)
+	if err != nil {
+		return nil, err
+	}
+	reader.Delimiter = r.Delimiter
+	reader.WithoutNull = r.WithoutNull
+	reader.AllowUnevenFields = r.AllowUnevenFields
+	reader.LazyQuotes = r.LazyQuotes
+	reader.Recovery = r.Recovery
+	reader.Limits = r.Limits
+	reader.Limits.MaxRecords = 0
+	reader.StripFormulaEscape = r.StripFormulaEscape
+	reader.FieldsPerRecord = fieldsPerRecord
+	reader.line = seg.line
+	reader.offset = reader.offset + seg.start
+	return reader, nil
+}
+
+func (r *ParallelReader) parseSegment(seg segment, fieldsPerRecord int) *segmentResult {
+	res := &segmentResult{}
+
+	reader, err := r.newReader(seg, fieldsPerRecord)
+	if err != nil {
+		res.err = err
+		return res
+	}
+
+	res.records = make([][]text.RawText, 0, 160)
+	res.lines = make([]int, 0, 160)
+	res.offsets = make([]int64, 0, 160)
+	for {
+		record, err := reader.parseRecord(r.WithoutNull, false, false)
+		if err != nil {
+			if err != io.EOF {
+				res.err = err
+			}
+			break
+		}
+		res.records = append(res.records, record)
+		res.lines = append(res.lines, reader.recordLine)
+		res.offsets = append(res.offsets, reader.recordOffset)
+	}
+
+	res.parsed = reader.record
+	res.errors = reader.Errors
+	res.fieldsPerRecord = reader.FieldsPerRecord
+	res.lineBreak = reader.DetectedLineBreak
+	res.enclosedAll = reader.EnclosedAll
+	return res
+}
+
+func (r *ParallelReader) splitSegments() ([]segment, error) {
+	chunkSize := int64(r.ChunkSize)
+	chunkNum := int((r.size - r.offset + chunkSize - 1) / chunkSize)
+	if chunkNum < 1 {
+		return nil, nil
+	}
+
+	scans := make([]chunkScan, chunkNum)
+	errs := make([]error, chunkNum)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, r.Workers)
+	for i := 0; i < chunkNum; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := r.offset + int64(i)*chunkSize
+			end := start + chunkSize
+			if r.size < end {
+				end = r.size
+			}
+			scans[i], errs[i] = r.scanChunk(start, end)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	segments := make([]segment, 0, chunkNum)
+	segments = append(segments, segment{start: r.offset, line: r.line})
+	state := stateRecordStart
+	line := r.line
+	for i := range scans {
+		if 0 < i {
+			if boundary := scans[i].boundary[state]; -1 < boundary && segments[len(segments)-1].start < boundary {
+				segments[len(segments)-1].end = boundary
+				segments = append(segments, segment{start: boundary, line: line + scans[i].lines[state]})
+			}
+		}
+		line = line + scans[i].totalLines
+		state = scans[i].endState[state]
+	}
+	segments[len(segments)-1].end = r.size
+
+	return segments, nil
+}
+
+func (r *ParallelReader) scanChunk(start int64, end int64) (chunkScan, error) {
+	var scan chunkScan
+
+	buf := make([]byte, end-start+1)
+	n, err := r.reader.ReadAt(buf, start)
+	if err != nil && err != io.EOF {
+		return scan, err
+	}
+
+	lookahead := -1
+	if int64(n) == end-start+1 {
+		lookahead = int(buf[n-1])
+	}
+	b := buf[:end-start]
+
+	delim := byte(r.Delimiter)
+	for s := scanState(0); s < scanStates; s++ {
+		state, boundary, lines, totalLines := scanBytes(b, lookahead, delim, s)
+		scan.endState[s] = state
+		scan.lines[s] = lines
+		scan.totalLines = totalLines
+		if boundary < 0 {
+			scan.boundary[s] = -1
+		} else {
+			scan.boundary[s] = start + int64(boundary)
+		}
+	}
+	return scan, nil
+}
+
+func (r *ParallelReader) firstRecordEnd() (int64, int, error) {
+	buf := make([]byte, 64*1024)
+	delim := byte(r.Delimiter)
+	state := stateRecordStart
+	hasContent := false
+	lines := 0
+
+	for pos := r.offset; pos < r.size; {
+		n, err := r.reader.ReadAt(buf, pos)
+		if err != nil && err != io.EOF {
+			return 0, 0, err
+		}
+		if n < 1 {
+			break
+		}
+
+		for i := 0; i < n; i++ {
+			c := buf[i]
+			lineBreak := false
+			switch c {
+			case '\n':
+				lineBreak = true
+			case '\r':
+				if i+1 < n {
+					lineBreak = buf[i+1] != '\n'
+				} else {
+					next := make([]byte, 1)
+					m, _ := r.reader.ReadAt(next, pos+int64(i)+1)
+					lineBreak = m < 1 || next[0] != '\n'
+				}
+				if !lineBreak {
+					continue
+				}
+			}
+
+			if lineBreak {
+				lines++
+			} else {
+				hasContent = true
+			}
+			state = nextScanState(state, c, lineBreak, delim)
+			if state == stateRecordStart && hasContent {
+				return pos + int64(i) + 1, lines, nil
+			}
+		}
+		pos = pos + int64(n)
+	}
+
+	return r.size, lines, nil
+}
+
+func scanBytes(b []byte, lookahead int, delim byte, state scanState) (scanState, int, int, int) {
+	boundary := -1
+	if state == stateRecordStart {
+		boundary = 0
+	}
+	lines := 0
+	totalLines := 0
+
+	for i, c := range b {
+		lineBreak := false
+		switch c {
+		case '\n':
+			lineBreak = true
+		case '\r':
+			next := lookahead
+			if i+1 < len(b) {
+				next = int(b[i+1])
+			}
+			if next == '\n' {
+				continue
+			}
+			lineBreak = true
+		}
+
+		if lineBreak {
+			totalLines++
+			if boundary < 0 {
+				lines++
+			}
+		}
+
+		state = nextScanState(state, c, lineBreak, delim)
+		if state == stateRecordStart && boundary < 0 {
+			boundary = i + 1
+		}
+	}
+
+	return state, boundary, lines, totalLines
+}
+
+func nextScanState(state scanState, c byte, lineBreak bool, delim byte) scanState {
+	switch state {
+	case stateQuoted:
+		if c == '"' {
+			return stateQuotedEscaped
+		}
+		return stateQuoted
+	case stateQuotedEscaped:
+		switch {
+		case lineBreak:
+			return stateRecordStart
+		case c == '"':
+			return stateQuoted
+		case c == delim:
+			return stateFieldStart
+		}
+		return stateUnquoted
+	case stateUnquoted:
+		switch {
+		case lineBreak:
+			return stateRecordStart
+		case c == delim:
+			return stateFieldStart
+		}
+		return stateUnquoted
+	default:
+		switch {
+		case lineBreak:
+			return stateRecordStart
+		case c == '"':
+			return stateQuoted
+		case c == delim:
+			return stateFieldStart
+		}
+		return stateUnquoted
+	}
+}
@@ -0,0 +1,191 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+var parallelReaderReadAllTests = []struct {
+	Name              string
+	Encoding          text.Encoding
+	Delimiter         rune
+	AllowUnevenFields bool
+	Recovery          RecoveryMode
+	Limits            text.Limits
+	Input             string
+}{
+	{
+		Name:     "Simple Records",
+		Encoding: text.UTF8,
+		Input:    strings.Repeat("a,b,c\nd,e,f\n", 20),
+	},
+	{
+		Name:     "Quoted Multi-line Fields",
+		Encoding: text.UTF8,
+		Input:    strings.Repeat("a,\"b\nb\nb\",c\r\n\"d\"\"\n,\",e,\"\"\r\n\n", 20),
+	},
+	{
+		Name:     "Stray Quotes In Unquoted Fields",
+		Encoding: text.UTF8,
+		Input:    strings.Repeat("a\",b\"\"b,\"c\nc\"\rd,e\",f\n", 20),
+	},
+	{
+		Name:      "Tab Delimiter",
+		Encoding:  text.UTF8,
+		Delimiter: '\t',
+		Input:     strings.Repeat("a\t\"b\tb\nb\"\tc\n", 20),
+	},
+	{
+		Name:     "Shift-JIS",
+		Encoding: text.SJIS,
+		Input:    strings.Repeat("a,\""+string([]byte{0x93, 0xfa, 0x0a, 0x96, 0x7b, 0x8c, 0xea})+"\",c\n", 20),
+	},
+	{
+		Name:     "UTF8 with BOM",
+		Encoding: text.UTF8M,
+		Input:    text.UTF8BOM + strings.Repeat("a,b,\"c\nc\"\n", 20),
+	},
+	{
+		Name:              "Uneven Fields",
+		Encoding:          text.UTF8,
+		AllowUnevenFields: true,
+		Input:             strings.Repeat("a,b\nc,\"d\n\",e\nf\n", 20),
+	},
+	{
+		Name:     "Wrong Number Of Fields",
+		Encoding: text.UTF8,
+		Input:    strings.Repeat("a,b,c\nd,\"e\ne\",f\n", 20) + "g,h\n" + strings.Repeat("a,b,c\n", 20),
+	},
+	{
+		Name:     "Unexpected Quote",
+		Encoding: text.UTF8,
+		Input:    strings.Repeat("a,b,c\n", 30) + "\"d\"d,e,f\n" + strings.Repeat("a,b,c\n", 20),
+	},
+	{
+		Name:     "Skip Records",
+		Encoding: text.UTF8,
+		Recovery: SkipRecord,
+		Input:    strings.Repeat("a,b,c\n\"d\"d,e,f\ng,h\n", 20),
+	},
+	{
+		Name:     "Records Limit In Multi-line Record",
+		Encoding: text.UTF8,
+		Limits:   text.Limits{MaxRecords: 30},
+		Input:    strings.Repeat("a,\"b\nb\nb\",c\n", 40),
+	},
+	{
+		Name:     "Field Size Limit In Multi-line Record",
+		Encoding: text.UTF8,
+		Limits:   text.Limits{MaxFieldSize: 5},
+		Input:    strings.Repeat("a,\"b\nb\",c\n", 30) + "d,\"e\ne\ne\",f\n",
+	},
+}
+
+func TestParallelReader_ReadAll(t *testing.T) {
+	for _, v := range parallelReaderReadAllTests {
+		r, _ := NewReader(strings.NewReader(v.Input), v.Encoding)
+		if v.Delimiter != 0 {
+			r.Delimiter = v.Delimiter
+		}
+		r.AllowUnevenFields = v.AllowUnevenFields
+		r.Recovery = v.Recovery
+		r.Limits = v.Limits
+		expect, expectErr := r.ReadAll()
+
+		for _, chunkSize := range []int{1, 2, 3, 5, 8, 13, 64, 1024} {
+			pr, err := NewParallelReader(strings.NewReader(v.Input), int64(len(v.Input)), v.Encoding)
+			if err != nil {
+				t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+			}
+			if v.Delimiter != 0 {
+				pr.Delimiter = v.Delimiter
+			}
+			pr.AllowUnevenFields = v.AllowUnevenFields
+			pr.Recovery = v.Recovery
+			pr.Limits = v.Limits
+			pr.ChunkSize = chunkSize
+			pr.Workers = 4
+
+			records, err := pr.ReadAll()
+			if expectErr != nil {
+				if err == nil {
+					t.Errorf("%s(chunk size %d): no error, want error %q", v.Name, chunkSize, expectErr.Error())
+				} else if !reflect.DeepEqual(err, expectErr) {
+					t.Errorf("%s(chunk size %d): error = %#v, want error %#v", v.Name, chunkSize, err, expectErr)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s(chunk size %d): unexpected error %q", v.Name, chunkSize, err.Error())
+				continue
+			}
+
+			if !reflect.DeepEqual(records, expect) {
+				t.Errorf("%s(chunk size %d): records = %q, want %q", v.Name, chunkSize, records, expect)
+			}
+			if !reflect.DeepEqual(pr.Errors, r.Errors) {
+				t.Errorf("%s(chunk size %d): errors = %v, want %v", v.Name, chunkSize, pr.Errors, r.Errors)
+			}
+			if pr.FieldsPerRecord != r.FieldsPerRecord {
+				t.Errorf("%s(chunk size %d): fields per record = %d, want %d", v.Name, chunkSize, pr.FieldsPerRecord, r.FieldsPerRecord)
+			}
+			if pr.DetectedLineBreak != r.DetectedLineBreak {
+				t.Errorf("%s(chunk size %d): line break = %q, want %q", v.Name, chunkSize, pr.DetectedLineBreak, r.DetectedLineBreak)
+			}
+			if pr.EnclosedAll != r.EnclosedAll {
+				t.Errorf("%s(chunk size %d): enclosed all = %t, want %t", v.Name, chunkSize, pr.EnclosedAll, r.EnclosedAll)
+			}
+		}
+	}
+}
+
+func TestParallelReader_ReadHeader(t *testing.T) {
+	input := "\nh1,\"h\n2\",h3\n" + strings.Repeat("a,b,c\n", 10) + "d,e\n"
+	outHeader := []string{"h1", "h\n2", "h3"}
+	expectErr := "line 14, column 0: wrong number of fields in line"
+
+	r, _ := NewParallelReader(strings.NewReader(input), int64(len(input)), text.UTF8)
+	r.ChunkSize = 4
+
+	header, err := r.ReadHeader()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(header, outHeader) {
+		t.Errorf("header = %q, want %q", header, outHeader)
+	}
+
+	records, err := r.ReadAll()
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	} else if err.(*ParseError).Record != 11 {
+		t.Errorf("record index = %d, want %d", err.(*ParseError).Record, 11)
+	}
+	if records != nil {
+		t.Errorf("records = %q, want nil", records)
+	}
+}
+
+func TestNewParallelReader(t *testing.T) {
+	expectErr := "parallel reading is not supported in UTF16"
+	_, err := NewParallelReader(strings.NewReader(""), 0, text.UTF16)
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+func BenchmarkParallelReader_ReadAll(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadAllBenchmarkText)
+		reader, _ := NewParallelReader(r, r.Size(), text.UTF8)
+		reader.ChunkSize = 16 * 1024
+		_, _ = reader.ReadAll()
+	}
+}
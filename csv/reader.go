@@ -4,47 +4,133 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
-	"fmt"
 	"io"
+	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/internal/source"
 )
 
+type RecoveryMode int
+
+const (
+	NoRecovery RecoveryMode = iota
+	SkipRecord
+	RepairRecord
+)
+
+type ParseError = text.ParseError
+
 type Reader struct {
 	Delimiter         rune
 	WithoutNull       bool
 	AllowUnevenFields bool
+	LazyQuotes        bool
+	Recovery          RecoveryMode
 	Encoding          text.Encoding
 
+	// ReuseRecord makes Read return a record sharing the reader's buffers.
+	// The record is valid only until the next call to Read.
+	ReuseRecord bool
+
+	// Limits restricts resources consumed by hostile input.
+	// MaxRecords counts every record read including the header.
+	Limits text.Limits
+
+	// StripFormulaEscape removes the escape character prepended by FormulaSanitizer.
+	StripFormulaEscape bool
+
+	// DelimiterString is used as the delimiter instead of Delimiter if it is not empty.
+	DelimiterString string
+
+	// RecordSeparator separates records instead of line breaks if it is not empty.
+	// Line breaks are then read as part of fields.
+	RecordSeparator string
+
+	// NullTokens are the values of unquoted fields read as NULL in addition to empty fields.
+	// QuotedNullTokens makes quoted fields equal to a token NULL as well.
+	NullTokens       text.NullTokens
+	QuotedNullTokens bool
+
+	// Columns selects the fields returned by Read in the order of the indices.
+	// Fields that are not selected are discarded while parsing. Nil selects all fields.
+	// Header.Indices converts column names into indices.
+	Columns []int
+
+	// Predicate filters records before they are materialized.
+	// It receives the fields at PredicateColumns, which share the reader's buffer
+	// and are valid only during the call. Records for which it returns false are skipped.
+	Predicate        func(fields []text.RawText) bool
+	PredicateColumns []int
+
+	// KeepSource keeps the original text of the last record read, which Source returns.
+	// It must be set before the first call to Read.
+	KeepSource bool
+
+	// Index records the position of every Interval-th record while reading.
+	// It must be set before the first call to Read.
+	// Record numbers count every record read including the header.
+	Index *text.RecordIndex
+
 	reader *bufio.Reader
+	tap    *source.Tap
 	line   int
 	column int
-
-	recordBuf     bytes.Buffer
-	fieldStartPos []int
-	fieldQuoted   []bool
+	record int
+
+	offset       int64
+	runeSize     int
+	recordOffset int64
+
+	// shifted is true in a run of double-byte characters of CP930 and CP939,
+	// and shiftSize is the size of the shift code counted for the last rune.
+	shifted       bool
+	prevShifted   bool
+	shiftSize     int
+	recordShifted bool
+	recordLine    int
+	skipUntil     int
+
+	recordBuf       bytes.Buffer
+	fieldStartPos   []int
+	fieldQuoted     []bool
+	lastRecord      []text.RawText
+	recordLineBreak text.LineBreak
+	blankLines      []text.LineBreak
+	selected        []bool
+	predicateFields []text.RawText
 
 	FieldsPerRecord int
 
 	DetectedLineBreak text.LineBreak
 	EnclosedAll       bool
+
+	// Errors collects the errors of malformed records that were skipped or repaired.
+	Errors []*ParseError
 }
 
 func NewReader(r io.Reader, enc text.Encoding) (*Reader, error) {
-	decoder, err := text.GetTransformDecoder(r, enc)
+	tap := source.NewTap(r)
+	decoder, err := text.GetTransformDecoder(tap, enc)
 	if err != nil {
 		return nil, err
 	}
+	_, bom, _ := text.SeekEncoding(enc)
 
 	return &Reader{
 		Delimiter:         ',',
 		WithoutNull:       false,
 		AllowUnevenFields: false,
+		LazyQuotes:        false,
+		Recovery:          NoRecovery,
 		Encoding:          enc,
 		reader:            bufio.NewReader(decoder),
+		tap:               tap,
 		line:              1,
 		column:            0,
+		offset:            int64(bom),
 		recordBuf:         bytes.Buffer{},
 		fieldStartPos:     make([]int, 0, 40),
 		fieldQuoted:       make([]bool, 0, 40),
@@ -53,12 +139,74 @@ func NewReader(r io.Reader, enc text.Encoding) (*Reader, error) {
 	}, nil
 }
 
-func (r *Reader) newError(s string) error {
-	return errors.New(fmt.Sprintf("line %d, column %d: %s", r.line, r.column, s))
+// NewIndexedReader returns a reader positioned at the record using the index.
+// Records from the nearest indexed record are skipped on the first call to Read.
+func NewIndexedReader(r io.ReadSeeker, enc text.Encoding, index *text.RecordIndex, record int) (*Reader, error) {
+	seekEnc, _, err := text.SeekEncoding(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := index.Lookup(record)
+	if !ok || entry.Offset < 1 {
+		if _, err = r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		reader, err := NewReader(r, enc)
+		if err != nil {
+			return nil, err
+		}
+		reader.skipUntil = record
+		return reader, nil
+	}
+
+	if _, err = r.Seek(entry.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader, err := NewReader(r, seekEnc)
+	if err != nil {
+		return nil, err
+	}
+	reader.Encoding = enc
+	reader.line = entry.Line
+	reader.record = entry.Record
+	reader.offset = entry.Offset
+	reader.tap.SetOffset(entry.Offset)
+	reader.skipUntil = record
+	return reader, nil
+}
+
+var errFilteredOut = errors.New("record is filtered out")
+
+func (r *Reader) newError(kind text.ErrorKind, s string) *ParseError {
+	return r.newErrorAt(kind, r.line, r.column, s)
+}
+
+func (r *Reader) newErrorAt(kind text.ErrorKind, line int, column int, s string) *ParseError {
+	return &ParseError{
+		Kind:    kind,
+		Line:    line,
+		Column:  column,
+		Offset:  r.offset,
+		Record:  r.record,
+		Field:   len(r.fieldStartPos),
+		Message: s,
+	}
+}
+
+func (r *Reader) newLimitError(limit text.Limit) *ParseError {
+	return r.newLimitErrorAt(limit, r.line)
+}
+
+func (r *Reader) newLimitErrorAt(limit text.Limit, line int) *ParseError {
+	lerr := r.Limits.NewLimitError(limit, line)
+	perr := r.newErrorAt(text.LimitExceededError, line, 0, lerr.Message())
+	perr.Err = lerr
+	return perr
 }
 
 func (r *Reader) ReadHeader() ([]string, error) {
-	record, err := r.parseRecord(true)
+	record, err := r.parseRecord(true, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -71,14 +219,59 @@ func (r *Reader) ReadHeader() ([]string, error) {
 }
 
 func (r *Reader) Read() ([]text.RawText, error) {
-	return r.parseRecord(r.WithoutNull)
+	return r.parseRecord(r.WithoutNull, r.ReuseRecord, true)
+}
+
+// ReadFields reads a record with the quoting of each field.
+// Fields padded by RepairRecord are not quoted. Fields read as NULL are marked as Null
+// so that Writer writes its NullToken for them.
+func (r *Reader) ReadFields() ([]Field, error) {
+	record, err := r.parseRecord(r.WithoutNull, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]Field, len(record))
+	for i, v := range record {
+		c := i
+		if r.Columns != nil {
+			c = r.Columns[i]
+		}
+		quoted := 0 <= c && c < len(r.fieldQuoted) && r.fieldQuoted[c]
+		fields[i] = Field{
+			Contents: string(v),
+			Quote:    quoted,
+			Unquoted: !quoted,
+			Null:     v == nil,
+		}
+	}
+	return fields, nil
+}
+
+// FieldQuoted returns whether each field of the last record read was enclosed in quotation marks.
+// The returned slice is valid only until the next call to Read.
+func (r *Reader) FieldQuoted() []bool {
+	return r.fieldQuoted
+}
+
+// BlankLines returns the line breaks of the blank lines skipped before the last record read,
+// or before EOF. The returned slice is valid only until the next call to Read.
+func (r *Reader) BlankLines() []text.LineBreak {
+	return r.blankLines
+}
+
+// RecordLineBreak returns the line break that terminated the last record read,
+// or RecordSeparator if it is set.
+// It returns an empty string if the record was terminated by EOF.
+func (r *Reader) RecordLineBreak() text.LineBreak {
+	return r.recordLineBreak
 }
 
 func (r *Reader) ReadAll() ([][]text.RawText, error) {
 	records := make([][]text.RawText, 0, 160)
 
 	for {
-		record, err := r.Read()
+		record, err := r.parseRecord(r.WithoutNull, false, true)
 		if err == io.EOF {
 			break
 		}
@@ -91,40 +284,113 @@ func (r *Reader) ReadAll() ([][]text.RawText, error) {
 	return records, nil
 }
 
-func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
+func (r *Reader) parseRecord(withoutNull bool, reuse bool, project bool) ([]text.RawText, error) {
+	r.blankLines = r.blankLines[:0]
+	for {
+		record, eol, err := r.parseRecordFields(withoutNull, reuse, project)
+		filtered := err == errFilteredOut
+		if err != nil && !filtered {
+			perr, ok := err.(*ParseError)
+			if !ok || perr.Kind == text.LimitExceededError || r.Recovery != SkipRecord {
+				return nil, err
+			}
+
+			r.Errors = append(r.Errors, perr)
+			r.record++
+			if !eol {
+				if err = r.skipLine(); err != nil && err != io.EOF {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		if r.Limits.RecordsExceeded(r.record + 1) {
+			perr := r.newLimitErrorAt(text.RecordsLimit, r.recordLine)
+			perr.Offset = r.recordOffset
+			perr.Field = 0
+			return nil, perr
+		}
+		r.record++
+		if r.Index != nil && !r.recordShifted {
+			r.Index.Add(r.record-1, r.recordOffset, r.recordLine)
+		}
+		if filtered || r.record <= r.skipUntil {
+			continue
+		}
+		return record, nil
+	}
+}
+
+func (r *Reader) parseRecordFields(withoutNull bool, reuse bool, project bool) ([]text.RawText, bool, error) {
 	r.recordBuf.Reset()
 	r.fieldStartPos = r.fieldStartPos[:0]
 	r.fieldQuoted = r.fieldQuoted[:0]
+	r.recordLineBreak = ""
+	r.tap.Enabled = r.KeepSource
+
+	projected := project && r.Columns != nil
+	if projected {
+		r.setSelectedColumns()
+	}
 
 	fieldIndex := 0
 	fieldPosition := 0
+	atEOF := false
 	for {
 		if 0 < r.FieldsPerRecord && r.FieldsPerRecord <= fieldIndex {
 			if !r.AllowUnevenFields {
-				return nil, r.newError("wrong number of fields in line")
+				perr := r.newError(text.FieldCountError, "wrong number of fields in line")
+				if r.Recovery != RepairRecord {
+					return nil, false, perr
+				}
+				r.Errors = append(r.Errors, perr)
+				if err := r.skipLine(); err != nil && err != io.EOF {
+					return nil, false, err
+				}
+				break
 			}
 			r.FieldsPerRecord = fieldIndex + 1
 		}
 
+		if r.Limits.FieldsPerRecordExceeded(fieldIndex + 1) {
+			return nil, false, r.newLimitError(text.FieldsPerRecordLimit)
+		}
+
+		if fieldIndex < 1 {
+			r.recordOffset = r.offset
+			r.recordShifted = r.shifted
+			r.recordLine = r.line
+			if r.KeepSource {
+				r.tap.Discard(r.recordOffset)
+			}
+		}
+
 		fieldPosition = r.recordBuf.Len()
 		quoted, eol, err := r.parseField()
 
 		if err != nil {
 			if err == io.EOF {
 				if fieldIndex < 1 && r.recordBuf.Len() < 1 {
-					return nil, io.EOF
+					return nil, true, io.EOF
 				}
+				atEOF = true
 			} else {
-				return nil, err
+				return nil, eol, err
 			}
 		}
 
-		if eol && fieldIndex < 1 && r.recordBuf.Len() < 1 {
+		if eol && fieldIndex < 1 && r.recordBuf.Len() < 1 && !quoted {
+			r.blankLines = append(r.blankLines, r.recordLineBreak)
+			r.recordLineBreak = ""
 			continue
 		}
 
 		r.fieldStartPos = append(r.fieldStartPos, fieldPosition)
 		r.fieldQuoted = append(r.fieldQuoted, quoted)
+		if projected && (len(r.selected) <= fieldIndex || !r.selected[fieldIndex]) {
+			r.recordBuf.Truncate(fieldPosition)
+		}
 		fieldIndex++
 
 		if eol {
@@ -132,36 +398,230 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 		}
 	}
 
+	fieldsLen := len(r.fieldStartPos)
 	if r.FieldsPerRecord < 1 {
 		r.FieldsPerRecord = fieldIndex
 	} else if fieldIndex < r.FieldsPerRecord {
 		if !r.AllowUnevenFields {
-			r.line--
-			return nil, r.newError("wrong number of fields in line")
+			line := r.line
+			if !atEOF {
+				line--
+			}
+			perr := r.newErrorAt(text.FieldCountError, line, r.column, "wrong number of fields in line")
+			if r.Recovery != RepairRecord {
+				return nil, true, perr
+			}
+			r.Errors = append(r.Errors, perr)
+			fieldsLen = r.FieldsPerRecord
 		}
 	}
 
-	record := make([]text.RawText, len(r.fieldStartPos))
-	recordStr := make([]byte, r.recordBuf.Len())
-	copy(recordStr, r.recordBuf.Bytes())
-	var endPos int
-	for i, pos := range r.fieldStartPos {
-		if i == len(r.fieldStartPos)-1 {
-			endPos = r.recordBuf.Len()
-		} else {
-			endPos = r.fieldStartPos[i+1]
+	if project && r.Predicate != nil {
+		r.predicateFields = r.predicateFields[:0]
+		for _, c := range r.PredicateColumns {
+			r.predicateFields = append(r.predicateFields, r.fieldValue(c, r.recordBuf.Bytes(), withoutNull))
 		}
+		if !r.Predicate(r.predicateFields) {
+			return nil, true, errFilteredOut
+		}
+	}
 
-		if pos == endPos && !r.fieldQuoted[i] {
-			if withoutNull {
-				record[i] = text.RawText{}
-			}
-		} else {
-			record[i] = recordStr[pos:endPos]
+	if project && r.Columns != nil {
+		fieldsLen = len(r.Columns)
+	}
+
+	var record []text.RawText
+	var recordStr []byte
+	if reuse {
+		if cap(r.lastRecord) < fieldsLen {
+			r.lastRecord = make([]text.RawText, fieldsLen)
+		}
+		record = r.lastRecord[:fieldsLen]
+		recordStr = r.recordBuf.Bytes()
+	} else {
+		record = make([]text.RawText, fieldsLen)
+		recordStr = make([]byte, r.recordBuf.Len())
+		copy(recordStr, r.recordBuf.Bytes())
+	}
+
+	if project && r.Columns != nil {
+		for i, c := range r.Columns {
+			record[i] = r.fieldValue(c, recordStr, withoutNull)
 		}
+	} else {
+		for i := range record {
+			record[i] = r.fieldValue(i, recordStr, withoutNull)
+		}
+	}
+
+	return record, true, nil
+}
+
+// fieldValue returns the i-th field of the last parsed record in buf.
+// Fields that do not exist are NULL.
+func (r *Reader) fieldValue(i int, buf []byte, withoutNull bool) text.RawText {
+	if i < 0 || len(r.fieldStartPos) <= i {
+		if withoutNull {
+			return text.RawText{}
+		}
+		return nil
+	}
+
+	pos := r.fieldStartPos[i]
+	endPos := len(buf)
+	if i < len(r.fieldStartPos)-1 {
+		endPos = r.fieldStartPos[i+1]
+	}
+
+	if (pos == endPos && !r.fieldQuoted[i]) ||
+		(0 < len(r.NullTokens) && (!r.fieldQuoted[i] || r.QuotedNullTokens) && r.NullTokens.Match(buf[pos:endPos])) {
+		if withoutNull {
+			return text.RawText{}
+		}
+		return nil
+	}
+	if r.StripFormulaEscape {
+		return UnsanitizeFormula(buf[pos:endPos:endPos])
+	}
+	return buf[pos:endPos:endPos]
+}
+
+func (r *Reader) setSelectedColumns() {
+	r.selected = r.selected[:0]
+	if r.Columns == nil {
+		return
+	}
+
+	mark := func(c int) {
+		if c < 0 {
+			return
+		}
+		for len(r.selected) <= c {
+			r.selected = append(r.selected, false)
+		}
+		r.selected[c] = true
+	}
+	for _, c := range r.Columns {
+		mark(c)
+	}
+	if r.Predicate != nil {
+		for _, c := range r.PredicateColumns {
+			mark(c)
+		}
+	}
+}
+
+// Source returns the original text of the last record read if KeepSource is true.
+func (r *Reader) Source() text.Source {
+	return text.NewSource(r.tap.Bytes(r.recordOffset, r.offset), r.Encoding, r.recordLine, r.recordOffset)
+}
+
+func (r *Reader) readRune() (rune, error) {
+	ch, size, err := r.reader.ReadRune()
+	if err == nil {
+		r.runeSize = text.SourceRuneSize(ch, size, r.Encoding)
+		r.prevShifted = r.shifted
+		r.shiftSize, r.shifted = text.ShiftCodeSize(ch, r.shifted, r.Encoding)
+		r.offset = r.offset + int64(r.runeSize+r.shiftSize)
+	}
+	return ch, err
+}
+
+func (r *Reader) unreadRune() error {
+	if err := r.reader.UnreadRune(); err != nil {
+		return err
 	}
+	r.offset = r.offset - int64(r.runeSize+r.shiftSize)
+	r.shifted = r.prevShifted
+	return nil
+}
+
+// skipLine skips the rest of the record. Fields are scanned with lazy quotes so that
+// line breaks in quoted fields do not end the record.
+func (r *Reader) skipLine() error {
+	pos := r.recordBuf.Len()
+	lazyQuotes, enclosedAll := r.LazyQuotes, r.EnclosedAll
+	r.LazyQuotes = true
+	defer func() {
+		r.LazyQuotes, r.EnclosedAll = lazyQuotes, enclosedAll
+	}()
 
-	return record, nil
+	for {
+		_, eol, err := r.parseField()
+		r.recordBuf.Truncate(pos)
+		if err != nil {
+			return err
+		}
+		if eol {
+			return nil
+		}
+	}
+}
+
+type separator int
+
+const (
+	noSeparator separator = iota
+	fieldSeparator
+	recordSeparator
+)
+
+func (r *Reader) separatorAt(ch rune) (separator, error) {
+	if len(r.DelimiterString) < 1 && ch == r.Delimiter {
+		return fieldSeparator, nil
+	}
+
+	first, second := r.DelimiterString, r.RecordSeparator
+	firstSep, secondSep := fieldSeparator, recordSeparator
+	if len(first) < len(second) {
+		first, second = second, first
+		firstSep, secondSep = secondSep, firstSep
+	}
+
+	if ok, err := r.consumeSequence(ch, first); ok || err != nil {
+		return firstSep, err
+	}
+	if ok, err := r.consumeSequence(ch, second); ok || err != nil {
+		return secondSep, err
+	}
+	return noSeparator, nil
+}
+
+// consumeSequence reports whether s starts with ch and the rest of s follows,
+// and consumes the rest if so.
+func (r *Reader) consumeSequence(ch rune, s string) (bool, error) {
+	if len(s) < 1 {
+		return false, nil
+	}
+	first, size := utf8.DecodeRuneInString(s)
+	if ch != first {
+		return false, nil
+	}
+
+	rest := s[size:]
+	if 0 < len(rest) {
+		b, err := r.reader.Peek(len(rest))
+		if err != nil && err != io.EOF {
+			return false, err
+		}
+		if string(b) != rest {
+			return false, nil
+		}
+		if _, err = r.reader.Discard(len(rest)); err != nil {
+			return false, err
+		}
+	}
+
+	for _, c := range rest {
+		r.column++
+		r.shiftSize, r.shifted = text.ShiftCodeSize(c, r.shifted, r.Encoding)
+		r.offset = r.offset + int64(text.SourceRuneSize(c, utf8.RuneLen(c), r.Encoding)+r.shiftSize)
+	}
+	if n := strings.Count(s, "\n"); 0 < n {
+		r.line = r.line + n
+		r.column = utf8.RuneCountInString(s[strings.LastIndexByte(s, '\n')+1:])
+	}
+	return true, nil
 }
 
 func (r *Reader) parseField() (bool, bool, error) {
@@ -171,93 +631,138 @@ func (r *Reader) parseField() (bool, bool, error) {
 
 	quoted := false
 	escaped := false
+	closed := false
 
 	var lineBreak text.LineBreak
 
 Read:
 	for {
+		if r.Limits.FieldSizeExceeded(r.recordBuf.Len() - startPos) {
+			return quoted, eol, r.newLimitError(text.FieldSizeLimit)
+		}
+		if r.Limits.RecordSizeExceeded(r.recordBuf.Len()) {
+			return quoted, eol, r.newLimitError(text.RecordSizeLimit)
+		}
+
 		lineBreak = ""
 
-		ch, _, err := r.reader.ReadRune()
+		ch, err := r.readRune()
 		r.column++
 
 		if err != nil {
 			if err == io.EOF {
-				if !escaped && quoted {
-					return quoted, eol, r.newError("extraneous \" in field")
+				if !escaped && quoted && !closed && !r.LazyQuotes {
+					perr := r.newError(text.QuoteError, "extraneous \" in field")
+					if r.Recovery != RepairRecord {
+						return quoted, eol, perr
+					}
+					r.Errors = append(r.Errors, perr)
 				}
 				eol = true
 			}
 			return quoted, eol, err
 		}
 
-		switch ch {
-		case '\r':
-			nxtCh, _, _ := r.reader.ReadRune()
-			if nxtCh == '\n' {
-				lineBreak = text.CRLF
-			} else {
-				if err = r.reader.UnreadRune(); err != nil {
-					return quoted, eol, err
+		sep := noSeparator
+		if !quoted || closed || escaped {
+			if len(r.DelimiterString) < 1 && len(r.RecordSeparator) < 1 {
+				if ch == r.Delimiter {
+					sep = fieldSeparator
 				}
-				lineBreak = text.CR
+			} else if sep, err = r.separatorAt(ch); err != nil {
+				return quoted, eol, err
 			}
-			ch = '\n'
-		case '\n':
-			lineBreak = text.LF
 		}
-		if ch == '\n' {
-			r.line++
-			r.column = 0
+
+		if sep == noSeparator {
+			switch ch {
+			case '\r':
+				nxtCh, e := r.readRune()
+				if nxtCh == '\n' {
+					lineBreak = text.CRLF
+				} else {
+					if e == nil {
+						if err = r.unreadRune(); err != nil {
+							return quoted, eol, err
+						}
+					}
+					lineBreak = text.CR
+				}
+				ch = '\n'
+			case '\n':
+				lineBreak = text.LF
+			}
+			if ch == '\n' {
+				r.line++
+				r.column = 0
+				if len(r.RecordSeparator) < 1 && (!quoted || closed || escaped) {
+					sep = recordSeparator
+				}
+			}
+		} else if sep == recordSeparator {
+			lineBreak = text.LineBreak(r.RecordSeparator)
 		}
 
-		if quoted {
+		if quoted && !closed {
 			if escaped {
-				switch ch {
-				case '"':
-					escaped = false
-					r.recordBuf.WriteRune(ch)
-					continue
-				case r.Delimiter:
+				switch {
+				case sep == fieldSeparator:
 					break Read
-				case '\n':
-					if r.DetectedLineBreak == "" {
+				case sep == recordSeparator:
+					if r.DetectedLineBreak == "" && len(r.RecordSeparator) < 1 {
 						r.DetectedLineBreak = lineBreak
 					}
+					r.recordLineBreak = lineBreak
 					eol = true
 					break Read
+				case ch == '"':
+					escaped = false
+					r.recordBuf.WriteRune(ch)
+					continue
 				default:
-					r.column--
-					return quoted, eol, r.newError("unexpected \" in field")
+					if !r.LazyQuotes {
+						perr := r.newErrorAt(text.QuoteError, r.line, r.column-1, "unexpected \" in field")
+						if r.Recovery != RepairRecord {
+							return quoted, eol, perr
+						}
+						r.Errors = append(r.Errors, perr)
+					}
+					closed = true
+					r.recordBuf.WriteRune('"')
 				}
 			}
 
-			switch ch {
-			case '"':
-				escaped = true
-			case '\n':
-				r.recordBuf.WriteString(lineBreak.Value())
-			default:
-				r.recordBuf.WriteRune(ch)
+			if !closed {
+				switch ch {
+				case '"':
+					escaped = true
+				case '\n':
+					r.recordBuf.WriteString(lineBreak.Value())
+				default:
+					r.recordBuf.WriteRune(ch)
+				}
+				continue
 			}
-			continue
 		}
 
-		switch ch {
-		case '\n':
-			if r.DetectedLineBreak == "" {
+		switch {
+		case sep == recordSeparator:
+			if r.DetectedLineBreak == "" && len(r.RecordSeparator) < 1 {
 				r.DetectedLineBreak = lineBreak
 			}
+			r.recordLineBreak = lineBreak
 			eol = true
 			break Read
-		case r.Delimiter:
+		case sep == fieldSeparator:
 			break Read
-		case '"':
+		case ch == '"':
 			if startPos == r.recordBuf.Len() {
 				quoted = true
 			} else {
 				r.recordBuf.WriteRune(ch)
 			}
+		case ch == '\n':
+			r.recordBuf.WriteString(lineBreak.Value())
 		default:
 			if r.EnclosedAll && unicode.IsLetter(ch) {
 				r.EnclosedAll = false
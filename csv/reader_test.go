@@ -1,6 +1,9 @@
 package csv
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -14,6 +17,7 @@ var readAllTests = []struct {
 	Delimiter         rune
 	WithoutNull       bool
 	AllowUnevenFields bool
+	LazyQuotes        bool
 	Input             string
 	Output            [][]text.RawText
 	LineBreak         text.LineBreak
@@ -197,6 +201,16 @@ var readAllTests = []struct {
 		Encoding: text.UTF8,
 		Error:    "line 1, column 11: unexpected \" in field",
 	},
+	{
+		Name:       "Lazy Quotes",
+		Input:      "a,\"b\"b,\"ccc\ncc\nd,e,",
+		Encoding:   text.UTF8,
+		LazyQuotes: true,
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b\"b"), text.RawText("ccc\ncc\nd,e,")},
+		},
+		LineBreak: "",
+	},
 	{
 		Name:     "Number Of Fields Is Less",
 		Input:    "a,b,c\nd,e\nf,g,h",
@@ -238,6 +252,7 @@ func TestReader_ReadAll(t *testing.T) {
 		}
 		r.WithoutNull = v.WithoutNull
 		r.AllowUnevenFields = v.AllowUnevenFields
+		r.LazyQuotes = v.LazyQuotes
 
 		records, err := r.ReadAll()
 
@@ -273,6 +288,163 @@ func TestReader_ReadAll(t *testing.T) {
 	}
 }
 
+var readAllWithRecoveryTests = []struct {
+	Name     string
+	Recovery RecoveryMode
+	Input    string
+	Output   [][]text.RawText
+	Errors   []ParseError
+}{
+	{
+		Name:     "Skip Records",
+		Recovery: SkipRecord,
+		Input:    "a,b,c\nd,\"e\"e,f\ng,h\ni,j,k,l\nm,n,o\np,\"q,r",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b"), text.RawText("c")},
+			{text.RawText("m"), text.RawText("n"), text.RawText("o")},
+		},
+		Errors: []ParseError{
+			{Kind: text.QuoteError, Line: 2, Column: 5, Offset: 12, Record: 1, Field: 1, Message: "unexpected \" in field"},
+			{Kind: text.FieldCountError, Line: 3, Column: 0, Offset: 19, Record: 2, Field: 2, Message: "wrong number of fields in line"},
+			{Kind: text.FieldCountError, Line: 4, Column: 6, Offset: 25, Record: 3, Field: 3, Message: "wrong number of fields in line"},
+			{Kind: text.QuoteError, Line: 6, Column: 7, Offset: 39, Record: 5, Field: 1, Message: "extraneous \" in field"},
+		},
+	},
+	{
+		Name:     "Repair Records",
+		Recovery: RepairRecord,
+		Input:    "a,b,c\nd,\"e\"e,f\ng,h\ni,j,k,l\nm,n,o\np,\"q,r",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b"), text.RawText("c")},
+			{text.RawText("d"), text.RawText("e\"e"), text.RawText("f")},
+			{text.RawText("g"), text.RawText("h"), nil},
+			{text.RawText("i"), text.RawText("j"), text.RawText("k")},
+			{text.RawText("m"), text.RawText("n"), text.RawText("o")},
+			{text.RawText("p"), text.RawText("q,r"), nil},
+		},
+		Errors: []ParseError{
+			{Kind: text.QuoteError, Line: 2, Column: 5, Offset: 12, Record: 1, Field: 1, Message: "unexpected \" in field"},
+			{Kind: text.FieldCountError, Line: 3, Column: 0, Offset: 19, Record: 2, Field: 2, Message: "wrong number of fields in line"},
+			{Kind: text.FieldCountError, Line: 4, Column: 6, Offset: 25, Record: 3, Field: 3, Message: "wrong number of fields in line"},
+			{Kind: text.QuoteError, Line: 6, Column: 7, Offset: 39, Record: 5, Field: 1, Message: "extraneous \" in field"},
+			{Kind: text.FieldCountError, Line: 6, Column: 7, Offset: 39, Record: 5, Field: 2, Message: "wrong number of fields in line"},
+		},
+	},
+	{
+		Name:     "Skip Records with Quoted Line Breaks",
+		Recovery: SkipRecord,
+		Input:    "a,b\nc,d,\"e\nf\"\ng,\"h\"x,\"i\nj\"\nk,l\n",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b")},
+			{text.RawText("k"), text.RawText("l")},
+		},
+		Errors: []ParseError{
+			{Kind: text.FieldCountError, Line: 2, Column: 4, Offset: 8, Record: 1, Field: 2, Message: "wrong number of fields in line"},
+			{Kind: text.QuoteError, Line: 4, Column: 5, Offset: 20, Record: 2, Field: 1, Message: "unexpected \" in field"},
+		},
+	},
+	{
+		Name:     "Repair Records with Quoted Line Breaks",
+		Recovery: RepairRecord,
+		Input:    "a,b\nc,d,\"e\nf\"\ng,\"h\"x,\"i\nj\"\nk,l\n",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b")},
+			{text.RawText("c"), text.RawText("d")},
+			{text.RawText("g"), text.RawText("h\"x")},
+			{text.RawText("k"), text.RawText("l")},
+		},
+		Errors: []ParseError{
+			{Kind: text.FieldCountError, Line: 2, Column: 4, Offset: 8, Record: 1, Field: 2, Message: "wrong number of fields in line"},
+			{Kind: text.QuoteError, Line: 4, Column: 5, Offset: 20, Record: 2, Field: 1, Message: "unexpected \" in field"},
+			{Kind: text.FieldCountError, Line: 4, Column: 7, Offset: 21, Record: 2, Field: 2, Message: "wrong number of fields in line"},
+		},
+	},
+}
+
+func TestReader_ReadAllWithRecovery(t *testing.T) {
+	for _, v := range readAllWithRecoveryTests {
+		r, _ := NewReader(strings.NewReader(v.Input), text.UTF8)
+		r.Recovery = v.Recovery
+
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %q, want %q", v.Name, records, v.Output)
+		}
+
+		errs := make([]ParseError, 0, len(r.Errors))
+		for _, e := range r.Errors {
+			errs = append(errs, *e)
+		}
+		if !reflect.DeepEqual(errs, v.Errors) {
+			t.Errorf("%s: errors = %v, want %v", v.Name, errs, v.Errors)
+		}
+	}
+}
+
+var readAllWithLimitsTests = []struct {
+	Name   string
+	Limits text.Limits
+	Input  string
+	Error  string
+}{
+	{
+		Name:   "Within Limits",
+		Limits: text.Limits{MaxFieldSize: 3, MaxRecordSize: 9, MaxFieldsPerRecord: 3, MaxRecords: 2},
+		Input:  "aaa,bbb,ccc\nd,\"e\ne\",f",
+	},
+	{
+		Name:   "Field Size",
+		Limits: text.Limits{MaxFieldSize: 3},
+		Input:  "aaa,bbb,ccc\nd,\"eeee",
+		Error:  "line 2: field size exceeds the limit of 3",
+	},
+	{
+		Name:   "Record Size",
+		Limits: text.Limits{MaxRecordSize: 8},
+		Input:  "aaa,bbb,ccc\nd,e,f",
+		Error:  "line 1: record size exceeds the limit of 8",
+	},
+	{
+		Name:   "Fields Per Record",
+		Limits: text.Limits{MaxFieldsPerRecord: 2},
+		Input:  "a,b,c,d,e,f,g,h",
+		Error:  "line 1: number of fields in record exceeds the limit of 2",
+	},
+	{
+		Name:   "Records",
+		Limits: text.Limits{MaxRecords: 2},
+		Input:  "a\nb\nc\nd",
+		Error:  "line 3: number of records exceeds the limit of 2",
+	},
+}
+
+func TestReader_ReadAllWithLimits(t *testing.T) {
+	for _, v := range readAllWithLimitsTests {
+		r, _ := NewReader(strings.NewReader(v.Input), text.UTF8)
+		r.Limits = v.Limits
+
+		_, err := r.ReadAll()
+		if err != nil {
+			if v.Error == "" {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if v.Error != err.Error() {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			} else if !errors.As(err, new(*text.LimitError)) && strings.Contains(v.Error, "limit") {
+				t.Errorf("%s: error type %T, want *text.LimitError", v.Name, err)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+		}
+	}
+}
+
 func TestReader_ReadHeader(t *testing.T) {
 	input := "h1,h2 ,h3\na,b,c\nd,e,f"
 	outHeader := []string{"h1", "h2 ", "h3"}
@@ -322,3 +494,520 @@ func BenchmarkReader_ReadAll(b *testing.B) {
 		_, _ = reader.ReadAll()
 	}
 }
+
+func TestReader_ReadWithReuseRecord(t *testing.T) {
+	input := "a,\"b\",c\nd,,\"\"\n\"ggg\nhh\",i,j"
+	output := [][]text.RawText{
+		{text.RawText("a"), text.RawText("b"), text.RawText("c")},
+		{text.RawText("d"), nil, text.RawText("")},
+		{text.RawText("ggg\nhh"), text.RawText("i"), text.RawText("j")},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.ReuseRecord = true
+
+	records := make([][]text.RawText, 0, len(output))
+	var first []text.RawText
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+
+		if first == nil {
+			first = record
+		} else if &first[0] != &record[0] {
+			t.Errorf("record is not reused")
+		}
+
+		cp := make([]text.RawText, len(record))
+		for i := range record {
+			if record[i] != nil {
+				cp[i] = append(text.RawText{}, record[i]...)
+			}
+		}
+		records = append(records, cp)
+	}
+
+	if !reflect.DeepEqual(records, output) {
+		t.Errorf("records = %q, want %q", records, output)
+	}
+}
+
+func BenchmarkReader_Read(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadAllBenchmarkText)
+		reader, _ := NewReader(r, text.UTF8)
+		for {
+			if _, err := reader.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkReader_ReadWithReuseRecord(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadAllBenchmarkText)
+		reader, _ := NewReader(r, text.UTF8)
+		reader.ReuseRecord = true
+		for {
+			if _, err := reader.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+var newIndexedReaderTests = []struct {
+	Name     string
+	Encoding text.Encoding
+	Input    string
+}{
+	{
+		Name:     "Multi-line Fields",
+		Encoding: text.UTF8,
+		Input:    "h1,h2\n\"a\nb\",c\r\n\nd,\"e\"\"\rf\"\ng,h\n\"i\",\"j\n\nk\"\nl,m",
+	},
+	{
+		Name:     "UTF8 with BOM",
+		Encoding: text.UTF8M,
+		Input:    text.UTF8BOM + "h1,h2\n\"日本\n語\",c\nd,e\nf,g",
+	},
+	{
+		Name:     "Shift-JIS",
+		Encoding: text.SJIS,
+		Input:    "h1,h2\n\"" + string([]byte{0x93, 0xfa, 0x0a, 0x96, 0x7b}) + "\",a\n" + string([]byte{0x8c, 0xea}) + ",b\nc,d\n",
+	},
+	{
+		Name:     "CP930",
+		Encoding: text.CP930,
+		Input:    "\xc8\xf1k\xc8\xf2%\x7f\x0eEbEf\x0f%\x0eH\xe7\x0f\x7fk\xc3%\x0eEb\x0fk\x7f\x0eEf\x0f\x7f%\xc3k\xc4%",
+	},
+	{
+		Name:     "UTF16LE with BOM",
+		Encoding: text.UTF16LEM,
+		Input:    text.UTF16LEBOM + "h\x001\x00,\x00h\x002\x00\n\x00\"\x00a\x00\n\x00b\x00\"\x00,\x00c\x00\n\x00d\x00,\x00e\x00",
+	},
+}
+
+func TestNewIndexedReader(t *testing.T) {
+	for _, v := range newIndexedReaderTests {
+		r, _ := NewReader(strings.NewReader(v.Input), v.Encoding)
+		r.Index = text.NewRecordIndex(1)
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+		}
+
+		buf := new(bytes.Buffer)
+		if _, err = r.Index.WriteTo(buf); err != nil {
+			t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+		}
+		index, err := text.ReadRecordIndex(buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+		}
+		if len(index.Entries) != len(records) {
+			t.Errorf("%s: entries = %d, want %d", v.Name, len(index.Entries), len(records))
+		}
+
+		for _, interval := range []int{1, 2, 3} {
+			idx := text.NewRecordIndex(interval)
+			for _, e := range index.Entries {
+				idx.Add(e.Record, e.Offset, e.Line)
+			}
+
+			for i := range records {
+				ir, err := NewIndexedReader(strings.NewReader(v.Input), v.Encoding, idx, i)
+				if err != nil {
+					t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+				}
+				result, err := ir.ReadAll()
+				if err != nil {
+					t.Errorf("%s(record %d, interval %d): unexpected error %q", v.Name, i, interval, err.Error())
+					continue
+				}
+				if !reflect.DeepEqual(result, records[i:]) {
+					t.Errorf("%s(record %d, interval %d): records = %q, want %q", v.Name, i, interval, result, records[i:])
+				}
+			}
+		}
+	}
+}
+
+func TestNewIndexedReader_Line(t *testing.T) {
+	input := "a,\"b\nb\"\n\nc,d\ne,\"f\"g\n"
+	expectErr := "line 5, column 5: unexpected \" in field"
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.Index = text.NewRecordIndex(1)
+	_, _ = r.ReadAll()
+
+	ir, _ := NewIndexedReader(strings.NewReader(input), text.UTF8, r.Index, 1)
+	_, err := ir.ReadAll()
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+var readAllWithSeparatorsTests = []struct {
+	Name              string
+	DelimiterString   string
+	RecordSeparator   string
+	AllowUnevenFields bool
+	Input             string
+	Output            [][]text.RawText
+	Error             string
+}{
+	{
+		Name:            "Double Pipe",
+		DelimiterString: "||",
+		Input:           "a||\"b||c\"||d|e\nf|||g||\"h\"\"\"",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b||c"), text.RawText("d|e")},
+			{text.RawText("f"), text.RawText("|g"), text.RawText("h\"")},
+		},
+	},
+	{
+		Name:              "Tilde Pipe Tilde",
+		DelimiterString:   "~|~",
+		AllowUnevenFields: true,
+		Input:             "a~|~b~|c~|~~|~\r\nd~|~\"e~|~\"~|~f",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b~|c"), nil, nil},
+			{text.RawText("d"), text.RawText("e~|~"), text.RawText("f")},
+		},
+	},
+	{
+		Name:            "Unit and Record Separators",
+		DelimiterString: "\x1f",
+		RecordSeparator: "\x1e",
+		Input:           "a\x1fb\nb\x1e\"c\x1e\"\x1fd\x1e",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b\nb")},
+			{text.RawText("c\x1e"), text.RawText("d")},
+		},
+	},
+	{
+		Name:            "Multi-character Record Separator",
+		RecordSeparator: "\x1f\x1e",
+		Input:           "a,b\x1fc\x1f\x1ed,\"e\x1f\x1e\"",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("b\x1fc")},
+			{text.RawText("d"), text.RawText("e\x1f\x1e")},
+		},
+	},
+	{
+		Name:            "Unexpected Quote",
+		DelimiterString: "\x1f",
+		RecordSeparator: "\x1e",
+		Input:           "a\x1f\"b\"c\x1e",
+		Error:           "line 1, column 5: unexpected \" in field",
+	},
+}
+
+func TestReader_ReadAllWithSeparators(t *testing.T) {
+	for _, v := range readAllWithSeparatorsTests {
+		r, _ := NewReader(strings.NewReader(v.Input), text.UTF8)
+		r.DelimiterString = v.DelimiterString
+		r.RecordSeparator = v.RecordSeparator
+		r.AllowUnevenFields = v.AllowUnevenFields
+
+		records, err := r.ReadAll()
+		if err != nil {
+			if v.Error == "" {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if v.Error != err.Error() {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %q, want %q", v.Name, records, v.Output)
+		}
+	}
+}
+
+var readAllWithProjectionTests = []struct {
+	Name             string
+	Columns          []int
+	PredicateColumns []int
+	Predicate        func(fields []text.RawText) bool
+	WithoutNull      bool
+	Input            string
+	Output           [][]text.RawText
+}{
+	{
+		Name:    "Columns",
+		Columns: []int{2, 0},
+		Input:   "a,\"b\nb\",c,d\ne,f,,h",
+		Output: [][]text.RawText{
+			{text.RawText("c"), text.RawText("a")},
+			{nil, text.RawText("e")},
+		},
+	},
+	{
+		Name:        "Columns Out Of Range",
+		Columns:     []int{1, 5},
+		WithoutNull: true,
+		Input:       "a,b\nc,",
+		Output: [][]text.RawText{
+			{text.RawText("b"), {}},
+			{{}, {}},
+		},
+	},
+	{
+		Name:             "Predicate",
+		PredicateColumns: []int{1},
+		Predicate: func(fields []text.RawText) bool {
+			return string(fields[0]) == "x"
+		},
+		Input: "a,x,1\nb,y,2\nc,x,3",
+		Output: [][]text.RawText{
+			{text.RawText("a"), text.RawText("x"), text.RawText("1")},
+			{text.RawText("c"), text.RawText("x"), text.RawText("3")},
+		},
+	},
+	{
+		Name:             "Predicate On A Column Not Selected",
+		Columns:          []int{2},
+		PredicateColumns: []int{1},
+		Predicate: func(fields []text.RawText) bool {
+			return string(fields[0]) != "y"
+		},
+		Input: "a,x,1\nb,y,2\nc,\"z\",3",
+		Output: [][]text.RawText{
+			{text.RawText("1")},
+			{text.RawText("3")},
+		},
+	},
+}
+
+func TestReader_ReadAllWithProjection(t *testing.T) {
+	for _, v := range readAllWithProjectionTests {
+		r, _ := NewReader(strings.NewReader(v.Input), text.UTF8)
+		r.Columns = v.Columns
+		r.PredicateColumns = v.PredicateColumns
+		r.Predicate = v.Predicate
+		r.WithoutNull = v.WithoutNull
+
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %q, want %q", v.Name, records, v.Output)
+		}
+	}
+}
+
+func TestReader_ReadHeaderWithProjection(t *testing.T) {
+	r, _ := NewReader(strings.NewReader("h1,h2,h3\na,b,c\n"), text.UTF8)
+	r.Columns = []int{1}
+
+	header, err := r.ReadHeader()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(header, []string{"h1", "h2", "h3"}) {
+		t.Errorf("header = %q, want %q", header, []string{"h1", "h2", "h3"})
+	}
+
+	fields, err := r.ReadFields()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(fields, []Field{{Contents: "b", Unquoted: true}}) {
+		t.Errorf("fields = %v, want %v", fields, []Field{{Contents: "b", Unquoted: true}})
+	}
+}
+
+func TestReader_ReadWithPredicateAllocations(t *testing.T) {
+	input := strings.Repeat("a,\"b\nb\",c\n", 200)
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.Columns = []int{0}
+	r.PredicateColumns = []int{2}
+	r.Predicate = func(fields []text.RawText) bool {
+		return false
+	}
+
+	var err error
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _, err = r.parseRecordFields(false, false, true)
+	})
+	if err != errFilteredOut {
+		t.Fatalf("error = %v, want %v", err, errFilteredOut)
+	}
+	if 0 < allocs {
+		t.Errorf("allocations = %v, want 0", allocs)
+	}
+}
+
+var readAllWithNullTokensTests = []struct {
+	Name             string
+	NullTokens       text.NullTokens
+	QuotedNullTokens bool
+	WithoutNull      bool
+	Input            string
+	Output           [][]text.RawText
+}{
+	{
+		Name:       "Unquoted Tokens",
+		NullTokens: text.NullTokens{"\\N", "NA"},
+		Input:      "\\N,\"\\N\",NA,\"\",,N",
+		Output: [][]text.RawText{
+			{nil, text.RawText("\\N"), nil, {}, nil, text.RawText("N")},
+		},
+	},
+	{
+		Name:             "Quoted Tokens",
+		NullTokens:       text.NullTokens{"NULL"},
+		QuotedNullTokens: true,
+		Input:            "NULL,\"NULL\",\"\"",
+		Output: [][]text.RawText{
+			{nil, nil, {}},
+		},
+	},
+	{
+		Name:        "Without Null",
+		NullTokens:  text.NullTokens{"NULL"},
+		WithoutNull: true,
+		Input:       "NULL,a",
+		Output: [][]text.RawText{
+			{{}, text.RawText("a")},
+		},
+	},
+}
+
+func TestReader_ReadAllWithNullTokens(t *testing.T) {
+	for _, v := range readAllWithNullTokensTests {
+		r, _ := NewReader(strings.NewReader(v.Input), text.UTF8)
+		r.NullTokens = v.NullTokens
+		r.QuotedNullTokens = v.QuotedNullTokens
+		r.WithoutNull = v.WithoutNull
+
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %#v, want %#v", v.Name, records, v.Output)
+		}
+	}
+}
+
+func TestReader_ReadFieldsWithNullTokens(t *testing.T) {
+	input := "a,\\N,\"\\N\",\"\"\n"
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.NullTokens = text.NullTokens{"\\N"}
+
+	fields, err := r.ReadFields()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	expect := []Field{
+		{Contents: "a", Unquoted: true},
+		{Contents: "", Unquoted: true, Null: true},
+		{Contents: "\\N", Quote: true},
+		{Contents: "", Quote: true},
+	}
+	if !reflect.DeepEqual(fields, expect) {
+		t.Errorf("fields = %#v, want %#v", fields, expect)
+	}
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, text.LF, text.UTF8)
+	w.NullToken = "\\N"
+	if err = w.WriteWithLineBreak(fields, r.RecordLineBreak()); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	_ = w.Flush()
+	if buf.String() != input {
+		t.Errorf("result = %q, want %q", buf.String(), input)
+	}
+}
+
+var readerSourceTests = []struct {
+	Name     string
+	Encoding text.Encoding
+	Input    string
+	Output   []text.Source
+}{
+	{
+		Name:     "Multi-line Fields",
+		Encoding: text.UTF8,
+		Input:    "a,\"b\r\nb\"\r\n\nc,d",
+		Output: []text.Source{
+			{Bytes: []byte("a,\"b\r\nb\"\r\n"), Text: "a,\"b\r\nb\"\r\n", Line: 1, Offset: 0},
+			{Bytes: []byte("c,d"), Text: "c,d", Line: 4, Offset: 11},
+		},
+	},
+	{
+		Name:     "UTF8 with BOM",
+		Encoding: text.UTF8M,
+		Input:    text.UTF8BOM + "日本,語\n",
+		Output: []text.Source{
+			{Bytes: []byte("日本,語\n"), Text: "日本,語\n", Line: 1, Offset: 3},
+		},
+	},
+	{
+		Name:     "Shift-JIS",
+		Encoding: text.SJIS,
+		Input:    "a,b\n" + string([]byte{0x93, 0xfa, 0x96, 0x7b}) + ",c\n",
+		Output: []text.Source{
+			{Bytes: []byte("a,b\n"), Text: "a,b\n", Line: 1, Offset: 0},
+			{Bytes: []byte{0x93, 0xfa, 0x96, 0x7b, ',', 'c', '\n'}, Text: "日本,c\n", Line: 2, Offset: 4},
+		},
+	},
+}
+
+func TestReader_Source(t *testing.T) {
+	for _, v := range readerSourceTests {
+		r, _ := NewReader(strings.NewReader(v.Input), v.Encoding)
+		r.KeepSource = true
+
+		for i, expect := range v.Output {
+			if _, err := r.Read(); err != nil {
+				t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+			}
+			if result := r.Source(); !reflect.DeepEqual(result, expect) {
+				t.Errorf("%s: source %d = %#v, want %#v", v.Name, i, result, expect)
+			}
+		}
+	}
+}
+
+func TestReader_ReadParseError(t *testing.T) {
+	input := "a,b\n日本,\"c\"d\n"
+	expect := &ParseError{
+		Kind:    text.QuoteError,
+		Line:    2,
+		Column:  6,
+		Offset:  15,
+		Record:  1,
+		Field:   1,
+		Message: "unexpected \" in field",
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	_, err := r.ReadAll()
+
+	var perr *text.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *text.ParseError", err)
+	}
+	if !reflect.DeepEqual(perr, expect) {
+		t.Errorf("error = %#v, want %#v", perr, expect)
+	}
+}
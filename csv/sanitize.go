@@ -0,0 +1,67 @@
+package csv
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FormulaTriggers are the leading characters that make spreadsheet applications
+// interpret a cell as a formula.
+const FormulaTriggers = "=+-@\t\r"
+
+// FormulaEscape is the character prepended to sanitized values.
+const FormulaEscape = '\''
+
+// NumericPattern matches numeric-looking values such as "-1", "+1.5" and "-2e10".
+var NumericPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// FormulaSanitizer prepends FormulaEscape to values that can be interpreted as formulas,
+// as recommended by OWASP.
+//
+// Values starting with FormulaEscape followed by a value to be sanitized are also escaped
+// so that UnsanitizeFormula restores the original value.
+type FormulaSanitizer struct {
+	// AllowList is the list of patterns of values written as they are even if
+	// they start with a formula trigger.
+	AllowList []*regexp.Regexp
+}
+
+// NewFormulaSanitizer returns a sanitizer that allows numeric-looking values.
+func NewFormulaSanitizer() *FormulaSanitizer {
+	return &FormulaSanitizer{
+		AllowList: []*regexp.Regexp{NumericPattern},
+	}
+}
+
+func (s *FormulaSanitizer) Sanitize(value string) (string, bool) {
+	if !isFormulaLike(value) {
+		return value, false
+	}
+	for _, p := range s.AllowList {
+		if p.MatchString(value) {
+			return value, false
+		}
+	}
+	return string(FormulaEscape) + value, true
+}
+
+// UnsanitizeFormula removes FormulaEscape prepended by FormulaSanitizer.
+func UnsanitizeFormula(value []byte) []byte {
+	if 1 < len(value) && value[0] == FormulaEscape && isFormulaLike(string(value[1:])) {
+		return value[1:]
+	}
+	return value
+}
+
+func isFormulaLike(value string) bool {
+	for 0 < len(value) {
+		if strings.IndexByte(FormulaTriggers, value[0]) != -1 {
+			return true
+		}
+		if value[0] != FormulaEscape {
+			return false
+		}
+		value = value[1:]
+	}
+	return false
+}
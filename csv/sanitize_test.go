@@ -0,0 +1,131 @@
+package csv
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+var formulaSanitizerSanitizeTests = []struct {
+	Name      string
+	AllowList []*regexp.Regexp
+	Value     string
+	Expect    string
+	Sanitized bool
+}{
+	{
+		Name:   "Plain Value",
+		Value:  "abc",
+		Expect: "abc",
+	},
+	{
+		Name:      "Formula",
+		Value:     "=SUM(A1:A2)",
+		Expect:    "'=SUM(A1:A2)",
+		Sanitized: true,
+	},
+	{
+		Name:      "At Sign",
+		Value:     "@cmd",
+		Expect:    "'@cmd",
+		Sanitized: true,
+	},
+	{
+		Name:      "Tab",
+		Value:     "\t=1",
+		Expect:    "'\t=1",
+		Sanitized: true,
+	},
+	{
+		Name:      "Carriage Return",
+		Value:     "\r=1",
+		Expect:    "'\r=1",
+		Sanitized: true,
+	},
+	{
+		Name:   "Negative Number",
+		Value:  "-1.5e3",
+		Expect: "-1.5e3",
+	},
+	{
+		Name:      "Negative Number Without Allow List",
+		AllowList: []*regexp.Regexp{},
+		Value:     "-1",
+		Expect:    "'-1",
+		Sanitized: true,
+	},
+	{
+		Name:      "Minus Followed By Formula",
+		Value:     "-1+cmd|' /C calc'!A0",
+		Expect:    "'-1+cmd|' /C calc'!A0",
+		Sanitized: true,
+	},
+	{
+		Name:      "Escaped Value",
+		Value:     "'=1",
+		Expect:    "''=1",
+		Sanitized: true,
+	},
+	{
+		Name:   "Single Quote",
+		Value:  "'abc",
+		Expect: "'abc",
+	},
+}
+
+func TestFormulaSanitizer_Sanitize(t *testing.T) {
+	for _, v := range formulaSanitizerSanitizeTests {
+		s := NewFormulaSanitizer()
+		if v.AllowList != nil {
+			s.AllowList = v.AllowList
+		}
+
+		result, sanitized := s.Sanitize(v.Value)
+		if result != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, result, v.Expect)
+		}
+		if sanitized != v.Sanitized {
+			t.Errorf("%s: sanitized = %t, want %t", v.Name, sanitized, v.Sanitized)
+		}
+	}
+}
+
+func TestWriter_WriteWithSanitizer(t *testing.T) {
+	records := [][]Field{
+		{{Contents: "=1+1"}, {Contents: "-1"}, {Contents: "'@a"}, {Contents: "abc"}},
+		{{Contents: "+a\"b"}, {Contents: "-"}, {Contents: "'"}, {Contents: ""}},
+	}
+	expect := "\"'=1+1\",-1,\"''@a\",abc\n" +
+		"\"'+a\"\"b\",\"'-\",',"
+
+	w := new(bytes.Buffer)
+	e, _ := NewWriter(w, text.LF, text.UTF8)
+	e.Sanitizer = NewFormulaSanitizer()
+	for _, r := range records {
+		_ = e.Write(r)
+	}
+	_ = e.Flush()
+
+	if w.String() != expect {
+		t.Errorf("result = %q, want %q", w.String(), expect)
+	}
+
+	r, _ := NewReader(strings.NewReader(w.String()), text.UTF8)
+	r.StripFormulaEscape = true
+	result, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	output := [][]text.RawText{
+		{text.RawText("=1+1"), text.RawText("-1"), text.RawText("'@a"), text.RawText("abc")},
+		{text.RawText("+a\"b"), text.RawText("-"), text.RawText("'"), nil},
+	}
+	if !reflect.DeepEqual(result, output) {
+		t.Errorf("records = %q, want %q", result, output)
+	}
+}
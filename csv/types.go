@@ -3,6 +3,13 @@ package csv
 type Field struct {
 	Contents string
 	Quote    bool
+
+	// Null makes Writer write NullToken instead of Contents.
+	Null bool
+
+	// Unquoted is set by Reader.ReadFields for fields read without quotation marks.
+	// Writer writes them without quotation marks unless they would be read differently.
+	Unquoted bool
 }
 
 func NewField(contents string, quote bool) Field {
@@ -11,3 +18,9 @@ func NewField(contents string, quote bool) Field {
 		Quote:    quote,
 	}
 }
+
+func NewNullField() Field {
+	return Field{
+		Null: true,
+	}
+}
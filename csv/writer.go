@@ -3,6 +3,7 @@ package csv
 import (
 	"bufio"
 	"io"
+	"strings"
 
 	"github.com/mithrandie/go-text"
 )
@@ -12,9 +13,24 @@ const QuotationMark = 0x22
 type Writer struct {
 	Delimiter rune
 
-	writer    *bufio.Writer
-	lineBreak string
-	appended  bool
+	// DelimiterString is used as the delimiter instead of Delimiter if it is not empty.
+	DelimiterString string
+
+	// RecordSeparator separates records instead of the line break if it is not empty.
+	RecordSeparator string
+
+	// NullToken is written without quotes for NULL fields.
+	// Values equal to a non-empty NullToken are quoted to be distinguished from NULL.
+	NullToken string
+
+	// Sanitizer escapes values that can be interpreted as formulas by spreadsheet applications.
+	// Sanitized values are always quoted. Nil disables sanitization.
+	Sanitizer *FormulaSanitizer
+
+	writer     *bufio.Writer
+	lineBreak  string
+	appended   bool
+	terminated bool
 }
 
 func NewWriter(w io.Writer, lineBreak text.LineBreak, enc text.Encoding) (*Writer, error) {
@@ -31,27 +47,98 @@ func NewWriter(w io.Writer, lineBreak text.LineBreak, enc text.Encoding) (*Write
 }
 
 func (e *Writer) Write(record []Field) error {
-	if e.appended {
-		if _, err := e.writer.WriteString(e.lineBreak); err != nil {
+	if e.appended && !e.terminated {
+		separator := e.lineBreak
+		if 0 < len(e.RecordSeparator) {
+			separator = e.RecordSeparator
+		}
+		if _, err := e.writer.WriteString(separator); err != nil {
 			return err
 		}
 	} else {
 		e.appended = true
 	}
+	e.terminated = false
+
+	return e.writeFields(record)
+}
+
+// WriteWithLineBreak writes the record terminated by the line break.
+// No line break is written if lineBreak is empty.
+//
+// Together with Reader.ReadFields and Reader.RecordLineBreak, it reproduces
+// the original text of a record. Blank lines are reproduced by WriteBlankLines.
+func (e *Writer) WriteWithLineBreak(record []Field, lineBreak text.LineBreak) error {
+	if err := e.Write(record); err != nil {
+		return err
+	}
+	if _, err := e.writer.WriteString(lineBreak.Value()); err != nil {
+		return err
+	}
+	e.terminated = true
+	return nil
+}
+
+// WriteBlankLines writes blank lines terminated by the line breaks.
+// Together with Reader.BlankLines, it reproduces the blank lines preceding a record.
+func (e *Writer) WriteBlankLines(lineBreaks []text.LineBreak) error {
+	if len(lineBreaks) < 1 {
+		return nil
+	}
+
+	if e.appended && !e.terminated {
+		separator := e.lineBreak
+		if 0 < len(e.RecordSeparator) {
+			separator = e.RecordSeparator
+		}
+		if _, err := e.writer.WriteString(separator); err != nil {
+			return err
+		}
+	}
+	for _, lb := range lineBreaks {
+		if _, err := e.writer.WriteString(lb.Value()); err != nil {
+			return err
+		}
+	}
+	e.appended = true
+	e.terminated = true
+	return nil
+}
 
+func (e *Writer) writeFields(record []Field) error {
 	for i := 0; i < len(record); i++ {
 		if 0 < i {
-			if _, err := e.writer.WriteRune(e.Delimiter); err != nil {
+			if 0 < len(e.DelimiterString) {
+				if _, err := e.writer.WriteString(e.DelimiterString); err != nil {
+					return err
+				}
+			} else if _, err := e.writer.WriteRune(e.Delimiter); err != nil {
+				return err
+			}
+		}
+
+		if record[i].Null {
+			if _, err := e.writer.WriteString(e.NullToken); err != nil {
 				return err
 			}
+			continue
 		}
 
-		if record[i].Quote || e.includeDelimiterOrQuote(record[i].Contents) {
+		contents := record[i].Contents
+		quote := record[i].Quote || (0 < len(e.NullToken) && contents == e.NullToken)
+		if e.Sanitizer != nil {
+			var sanitized bool
+			if contents, sanitized = e.Sanitizer.Sanitize(contents); sanitized {
+				quote = true
+			}
+		}
+
+		if quote || e.needsQuote(contents, record[i].Unquoted) {
 			if err := e.writer.WriteByte(QuotationMark); err != nil {
 				return err
 			}
 
-			runes := []rune(record[i].Contents)
+			runes := []rune(contents)
 			pos := 0
 
 			for {
@@ -77,7 +164,7 @@ func (e *Writer) Write(record []Field) error {
 				return err
 			}
 		} else {
-			if _, err := e.writer.WriteString(record[i].Contents); err != nil {
+			if _, err := e.writer.WriteString(contents); err != nil {
 				return err
 			}
 		}
@@ -89,9 +176,47 @@ func (e *Writer) Flush() error {
 	return e.writer.Flush()
 }
 
+// needsQuote returns whether the contents must be enclosed in quotation marks.
+// Contents read without quotation marks are enclosed only if a reader would read them differently.
+func (e *Writer) needsQuote(s string, unquoted bool) bool {
+	if !unquoted {
+		return e.includeDelimiterOrQuote(s)
+	}
+	if 0 < len(s) && s[0] == QuotationMark {
+		return true
+	}
+	if len(e.RecordSeparator) < 1 && strings.ContainsAny(s, "\r\n") {
+		return true
+	}
+	return e.includeSeparator(s)
+}
+
 func (e *Writer) includeDelimiterOrQuote(s string) bool {
-	for _, r := range s {
-		if r == e.Delimiter || r == QuotationMark {
+	return strings.IndexByte(s, QuotationMark) != -1 || e.includeSeparator(s)
+}
+
+func (e *Writer) includeSeparator(s string) bool {
+	if 0 < len(e.DelimiterString) {
+		if includeSequence(s, e.DelimiterString) {
+			return true
+		}
+	} else if strings.ContainsRune(s, e.Delimiter) {
+		return true
+	}
+	return includeSequence(s, e.RecordSeparator)
+}
+
+// includeSequence reports whether the sequence is found in s followed by the sequence,
+// which means that a reader finds it before the end of s.
+func includeSequence(s string, seq string) bool {
+	if len(seq) < 1 {
+		return false
+	}
+	if strings.Contains(s, seq) {
+		return true
+	}
+	for i := 1; i < len(seq) && i <= len(s); i++ {
+		if strings.HasSuffix(s, seq[:i]) && strings.HasPrefix(seq[i:], seq[:len(seq)-i]) {
 			return true
 		}
 	}
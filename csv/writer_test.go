@@ -2,6 +2,9 @@ package csv
 
 import (
 	"bytes"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/mithrandie/go-text"
@@ -185,3 +188,182 @@ func TestWriter_Write(t *testing.T) {
 		}
 	}
 }
+
+var writerRoundTripTests = []struct {
+	Name     string
+	Encoding text.Encoding
+	Input    string
+}{
+	{
+		Name:     "Mixed Quoting",
+		Encoding: text.UTF8,
+		Input:    "\"a\",b,\"\"\n,\"d\"\"e\",\"f,g\"\n",
+	},
+	{
+		Name:     "Mixed Line Breaks",
+		Encoding: text.UTF8,
+		Input:    "a,\"b\r\nb\"\r\nc,d\re,f\ng,h",
+	},
+	{
+		Name:     "Quoted Field At EOF",
+		Encoding: text.UTF8,
+		Input:    "a,b\n\"c\",\"d\"",
+	},
+	{
+		Name:     "UTF8 with BOM",
+		Encoding: text.UTF8M,
+		Input:    text.UTF8BOM + "\"a\",b\r\n",
+	},
+	{
+		Name:     "Unquoted Quotation Marks",
+		Encoding: text.UTF8,
+		Input:    "a\"b,c\nd\"\",\"e\"\"\"\n",
+	},
+	{
+		Name:     "Blank Lines",
+		Encoding: text.UTF8,
+		Input:    "\r\na,b\n\n\r\nc,d\n\n",
+	},
+	{
+		Name:     "Quoted Empty Field",
+		Encoding: text.UTF8,
+		Input:    "x\n\"\"\ny\n",
+	},
+}
+
+func TestWriter_WriteWithLineBreak(t *testing.T) {
+	for _, v := range writerRoundTripTests {
+		r, _ := NewReader(strings.NewReader(v.Input), v.Encoding)
+		r.AllowUnevenFields = true
+
+		buf := new(bytes.Buffer)
+		w, _ := NewWriter(buf, text.LF, v.Encoding)
+
+		for {
+			fields, err := r.ReadFields()
+			if werr := w.WriteBlankLines(r.BlankLines()); werr != nil {
+				t.Fatalf("%s: unexpected error %q", v.Name, werr.Error())
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+			}
+			if err = w.WriteWithLineBreak(fields, r.RecordLineBreak()); err != nil {
+				t.Fatalf("%s: unexpected error %q", v.Name, err.Error())
+			}
+		}
+		_ = w.Flush()
+
+		if buf.String() != v.Input {
+			t.Errorf("%s: result = %q, want %q", v.Name, buf.String(), v.Input)
+		}
+	}
+}
+
+func TestReader_FieldQuoted(t *testing.T) {
+	r, _ := NewReader(strings.NewReader("\"a\",b,\"\"\n"), text.UTF8)
+	_, _ = r.Read()
+
+	expect := []bool{true, false, true}
+	if !reflect.DeepEqual(r.FieldQuoted(), expect) {
+		t.Errorf("quoted = %v, want %v", r.FieldQuoted(), expect)
+	}
+	if r.RecordLineBreak() != text.LF {
+		t.Errorf("line break = %q, want %q", r.RecordLineBreak(), text.LF)
+	}
+}
+
+var writerWriteWithSeparatorsTests = []struct {
+	Name            string
+	DelimiterString string
+	RecordSeparator string
+	Records         [][]Field
+	Expect          string
+}{
+	{
+		Name:            "Double Pipe",
+		DelimiterString: "||",
+		Records: [][]Field{
+			{{Contents: "a"}, {Contents: "b||c"}, {Contents: "d|e"}},
+			{{Contents: "f|"}, {Contents: "|g"}, {Contents: "h\""}},
+		},
+		Expect: "a||\"b||c\"||d|e\n\"f|\"|||g||\"h\"\"\"",
+	},
+	{
+		Name:            "Tilde Pipe Tilde",
+		DelimiterString: "~|~",
+		Records: [][]Field{
+			{{Contents: "a~"}, {Contents: "b~|"}, {Contents: "c~|~d"}, {Contents: "~|"}},
+		},
+		Expect: "a~~|~\"b~|\"~|~\"c~|~d\"~|~\"~|\"",
+	},
+	{
+		Name:            "Overlapping Delimiter",
+		DelimiterString: "aba",
+		Records: [][]Field{
+			{{Contents: "xab"}, {Contents: "xa"}, {Contents: "x"}},
+		},
+		Expect: "\"xab\"abaxaabax",
+	},
+	{
+		Name:            "Unit and Record Separators",
+		DelimiterString: "\x1f",
+		RecordSeparator: "\x1e",
+		Records: [][]Field{
+			{{Contents: "a"}, {Contents: "b\nb"}},
+			{{Contents: "c\x1e"}, {Contents: "d"}},
+		},
+		Expect: "a\x1fb\nb\x1e\"c\x1e\"\x1fd",
+	},
+}
+
+func TestWriter_WriteWithSeparators(t *testing.T) {
+	for _, v := range writerWriteWithSeparatorsTests {
+		buf := new(bytes.Buffer)
+		w, _ := NewWriter(buf, text.LF, text.UTF8)
+		w.DelimiterString = v.DelimiterString
+		w.RecordSeparator = v.RecordSeparator
+		for _, r := range v.Records {
+			_ = w.Write(r)
+		}
+		_ = w.Flush()
+
+		if buf.String() != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, buf.String(), v.Expect)
+			continue
+		}
+
+		r, _ := NewReader(strings.NewReader(buf.String()), text.UTF8)
+		r.DelimiterString = v.DelimiterString
+		r.RecordSeparator = v.RecordSeparator
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		for i, record := range records {
+			for j, f := range record {
+				if string(f) != v.Records[i][j].Contents {
+					t.Errorf("%s: read field %q, want %q", v.Name, f, v.Records[i][j].Contents)
+				}
+			}
+		}
+	}
+}
+
+func TestWriter_WriteWithNullToken(t *testing.T) {
+	record := []Field{NewNullField(), NewField("\\N", false), NewField("", false), NewField("a", true)}
+	expect := "\\N,\"\\N\",,\"a\""
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, text.LF, text.UTF8)
+	w.NullToken = "\\N"
+	_ = w.Write(record)
+	_ = w.Flush()
+
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+}
@@ -0,0 +1,274 @@
+package text
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Shift codes switching between single-byte and double-byte characters in CP930 and CP939.
+const (
+	ShiftOut byte = 0x0e
+	ShiftIn  byte = 0x0f
+)
+
+// IsEBCDICEncoding returns true if the encoding is an EBCDIC code page.
+func IsEBCDICEncoding(enc Encoding) bool {
+	switch enc {
+	case CP037, CP500, CP930, CP939:
+		return true
+	}
+	return false
+}
+
+// IsShiftEncoding returns true if double-byte characters in the encoding are enclosed in
+// shift-out and shift-in codes.
+func IsShiftEncoding(enc Encoding) bool {
+	return enc == CP930 || enc == CP939
+}
+
+type ebcdicEncoding struct {
+	enc  Encoding
+	sbcs *[256]rune
+	dbcs bool
+
+	once        sync.Once
+	sbcsEncoder map[rune]byte
+}
+
+var (
+	cp037Encoding = &ebcdicEncoding{enc: CP037, sbcs: &cp037DecodeTable}
+	cp500Encoding = &ebcdicEncoding{enc: CP500, sbcs: &cp500DecodeTable}
+	cp930Encoding = &ebcdicEncoding{enc: CP930, sbcs: &cp930DecodeTable, dbcs: true}
+	cp939Encoding = &ebcdicEncoding{enc: CP939, sbcs: &cp939DecodeTable, dbcs: true}
+)
+
+var (
+	dbcsOnce    sync.Once
+	dbcsEncoder map[rune]uint16
+)
+
+func getEBCDICEncoding(enc Encoding) *ebcdicEncoding {
+	switch enc {
+	case CP037:
+		return cp037Encoding
+	case CP500:
+		return cp500Encoding
+	case CP930:
+		return cp930Encoding
+	case CP939:
+		return cp939Encoding
+	}
+	return nil
+}
+
+func (e *ebcdicEncoding) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: &ebcdicDecoder{encoding: e}}
+}
+
+func (e *ebcdicEncoding) NewEncoder() *encoding.Encoder {
+	e.prepareEncoder()
+	return &encoding.Encoder{Transformer: &ebcdicEncoder{encoding: e}}
+}
+
+func (e *ebcdicEncoding) prepareEncoder() {
+	e.once.Do(func() {
+		e.sbcsEncoder = make(map[rune]byte, 256)
+		for i := len(e.sbcs) - 1; 0 <= i; i-- {
+			if e.sbcs[i] != utf8.RuneError {
+				e.sbcsEncoder[e.sbcs[i]] = byte(i)
+			}
+		}
+	})
+
+	if e.dbcs {
+		dbcsOnce.Do(func() {
+			dbcsEncoder = make(map[rune]uint16, 12000)
+			for i := len(cp300DecodeTable) - 1; 0 <= i; i-- {
+				if r := cp300DecodeTable[i]; r != 0 {
+					dbcsEncoder[rune(r)] = uint16(0x40+i/0xc0)<<8 | uint16(0x40+i%0xc0)
+				}
+			}
+		})
+	}
+}
+
+// isDoubleByte returns true if the rune is encoded as a double-byte character.
+func (e *ebcdicEncoding) isDoubleByte(r rune) bool {
+	if !e.dbcs {
+		return false
+	}
+	e.prepareEncoder()
+	if _, ok := e.sbcsEncoder[r]; ok {
+		return false
+	}
+	_, ok := dbcsEncoder[r]
+	return ok
+}
+
+func dbcsRune(b1 byte, b2 byte) rune {
+	if b1 < 0x40 || 0x7f < b1 || b2 < 0x40 {
+		return utf8.RuneError
+	}
+	if r := cp300DecodeTable[int(b1-0x40)*0xc0+int(b2-0x40)]; r != 0 {
+		return rune(r)
+	}
+	return utf8.RuneError
+}
+
+type ebcdicDecoder struct {
+	encoding *ebcdicEncoding
+	shifted  bool
+}
+
+func (d *ebcdicDecoder) Reset() {
+	d.shifted = false
+}
+
+func (d *ebcdicDecoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		c := src[nSrc]
+
+		if d.encoding.dbcs {
+			switch c {
+			case ShiftOut:
+				d.shifted = true
+				nSrc++
+				continue
+			case ShiftIn:
+				d.shifted = false
+				nSrc++
+				continue
+			}
+		}
+
+		var r rune
+		size := 1
+		if d.shifted && 0x40 <= c {
+			if len(src) < nSrc+2 {
+				if !atEOF {
+					return nDst, nSrc, transform.ErrShortSrc
+				}
+				r = utf8.RuneError
+			} else {
+				r = dbcsRune(c, src[nSrc+1])
+				size = 2
+			}
+		} else {
+			// Control codes are read as single bytes in the double-byte mode.
+			r = d.encoding.sbcs[c]
+		}
+
+		if len(dst) < nDst+utf8.RuneLen(r) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst = nDst + utf8.EncodeRune(dst[nDst:], r)
+		nSrc = nSrc + size
+	}
+	return nDst, nSrc, nil
+}
+
+type ebcdicEncoder struct {
+	encoding *ebcdicEncoding
+	shifted  bool
+}
+
+func (e *ebcdicEncoder) Reset() {
+	e.shifted = false
+}
+
+func (e *ebcdicEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if !utf8.FullRune(src[nSrc:]) && !atEOF {
+			return nDst, nSrc, transform.ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+
+		if b, ok := e.encoding.sbcsEncoder[r]; ok {
+			n := 1
+			if e.shifted {
+				n = 2
+			}
+			if len(dst) < nDst+n {
+				return nDst, nSrc, transform.ErrShortDst
+			}
+			if e.shifted {
+				dst[nDst] = ShiftIn
+				nDst++
+				e.shifted = false
+			}
+			dst[nDst] = b
+			nDst++
+			nSrc = nSrc + size
+			continue
+		}
+
+		code, ok := uint16(0), false
+		if e.encoding.dbcs {
+			code, ok = dbcsEncoder[r]
+		}
+		if !ok {
+			return nDst, nSrc, errors.New(fmt.Sprintf("%q cannot be encoded in %s", r, e.encoding.enc))
+		}
+
+		n := 2
+		if !e.shifted {
+			n = 3
+		}
+		if len(dst) < nDst+n {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		if !e.shifted {
+			dst[nDst] = ShiftOut
+			nDst++
+			e.shifted = true
+		}
+		dst[nDst] = byte(code >> 8)
+		dst[nDst+1] = byte(code)
+		nDst = nDst + 2
+		nSrc = nSrc + size
+	}
+
+	if atEOF && e.shifted {
+		if len(dst) < nDst+1 {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		dst[nDst] = ShiftIn
+		nDst++
+		e.shifted = false
+	}
+	return nDst, nSrc, nil
+}
+
+func ebcdicRuneByteSize(r rune, enc Encoding) int {
+	if getEBCDICEncoding(enc).isDoubleByte(r) {
+		return 2
+	}
+	return 1
+}
+
+// ebcdicByteSize returns the byte size including a shift-out and a shift-in code for each run of
+// double-byte characters.
+func ebcdicByteSize(s string, enc Encoding) int {
+	e := getEBCDICEncoding(enc)
+
+	size := 0
+	shifted := false
+	for _, c := range s {
+		if e.isDoubleByte(c) {
+			if !shifted {
+				size = size + 2
+				shifted = true
+			}
+			size = size + 2
+		} else {
+			size++
+			shifted = false
+		}
+	}
+	return size
+}
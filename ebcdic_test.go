@@ -0,0 +1,93 @@
+package text
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"testing/iotest"
+)
+
+var ebcdicTests = []struct {
+	Name     string
+	Encoding Encoding
+	Text     string
+	Encoded  []byte
+}{
+	{
+		Name:     "CP037",
+		Encoding: CP037,
+		Text:     "[a]!",
+		Encoded:  []byte{0xba, 0x81, 0xbb, 0x5a},
+	},
+	{
+		Name:     "CP500",
+		Encoding: CP500,
+		Text:     "[a]!",
+		Encoded:  []byte{0x4a, 0x81, 0x5a, 0x4f},
+	},
+	{
+		Name:     "CP930 Katakana and Double-Byte Characters",
+		Encoding: CP930,
+		Text:     "ｱA日",
+		Encoded:  []byte{0x81, 0xc1, 0x0e, 0x45, 0x62, 0x0f},
+	},
+	{
+		Name:     "CP939 Mixed",
+		Encoding: CP939,
+		Text:     "AB日本c\n語",
+		Encoded:  []byte{0xc1, 0xc2, 0x0e, 0x45, 0x62, 0x45, 0x66, 0x0f, 0x83, 0x25, 0x0e, 0x48, 0xe7, 0x0f},
+	},
+}
+
+func TestEBCDIC(t *testing.T) {
+	for _, v := range ebcdicTests {
+		encoded, err := Encode([]byte(v.Text), v.Encoding)
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(encoded, v.Encoded) {
+			t.Errorf("%s: encoded = %#v, want %#v", v.Name, encoded, v.Encoded)
+		}
+
+		r, _ := GetTransformDecoder(iotest.OneByteReader(bytes.NewReader(v.Encoded)), v.Encoding)
+		decoded, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		if string(decoded) != v.Text {
+			t.Errorf("%s: decoded = %q, want %q", v.Name, decoded, v.Text)
+		}
+
+		if size := ByteSize(v.Text, v.Encoding); size != len(v.Encoded) {
+			t.Errorf("%s: byte size = %d, want %d", v.Name, size, len(v.Encoded))
+		}
+	}
+}
+
+func TestEBCDIC_Writer(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, _ := GetTransformWriter(buf, CP930)
+	for _, s := range []string{"A", "日", "本", "B"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+	}
+
+	expect := []byte{0xc1, 0x0e, 0x45, 0x62, 0x45, 0x66, 0x0f, 0xc2}
+	if !reflect.DeepEqual(buf.Bytes(), expect) {
+		t.Errorf("result = %#v, want %#v", buf.Bytes(), expect)
+	}
+}
+
+func TestEBCDIC_InvalidDoubleByteCharacter(t *testing.T) {
+	result, err := Decode([]byte{0x0e, 0x40, 0x40, 0x41, 0x40, 0x0f, 0xc1}, CP930)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if string(result) != "　�A" {
+		t.Errorf("result = %q, want %q", result, "　�A")
+	}
+}
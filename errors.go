@@ -0,0 +1,68 @@
+package text
+
+import (
+	"fmt"
+)
+
+type ErrorKind int
+
+const (
+	SyntaxError ErrorKind = iota
+	QuoteError
+	FieldCountError
+	SeparatorError
+	CharacterBoundaryError
+	DataFormatError
+	RecordTypeError
+	RecordCountError
+	LimitExceededError
+)
+
+var ErrorKindLiteral = map[ErrorKind]string{
+	SyntaxError:            "syntax error",
+	QuoteError:             "quote error",
+	FieldCountError:        "field count error",
+	SeparatorError:         "separator error",
+	CharacterBoundaryError: "character boundary error",
+	DataFormatError:        "data format error",
+	RecordTypeError:        "record type error",
+	RecordCountError:       "record count error",
+	LimitExceededError:     "limit exceeded",
+}
+
+func (k ErrorKind) String() string {
+	return ErrorKindLiteral[k]
+}
+
+// ParseError is an error that occurred while reading a record.
+// Use errors.As to retrieve it from the errors returned by readers.
+type ParseError struct {
+	Kind ErrorKind
+
+	// Line and Column are the position where the error was detected.
+	// Column is not reported for LimitExceededError.
+	Line   int
+	Column int
+	// Offset is the byte offset in the source where the error was detected.
+	Offset int64
+	// Record is the zero-based number of the record counting every record read including the header.
+	Record int
+	// Field is the zero-based index of the field in the record.
+	Field int
+
+	Message string
+
+	// Err is the underlying error such as *LimitError.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Kind == LimitExceededError {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
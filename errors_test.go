@@ -0,0 +1,41 @@
+package text
+
+import (
+	"errors"
+	"testing"
+)
+
+var parseErrorTests = []struct {
+	Name   string
+	Error  *ParseError
+	Expect string
+}{
+	{
+		Name:   "Syntax Error",
+		Error:  &ParseError{Kind: QuoteError, Line: 2, Column: 5, Message: "extraneous \" in field"},
+		Expect: "line 2, column 5: extraneous \" in field",
+	},
+	{
+		Name:   "Limit Exceeded",
+		Error:  &ParseError{Kind: LimitExceededError, Line: 3, Column: 8, Message: "field size exceeds the limit of 10"},
+		Expect: "line 3: field size exceeds the limit of 10",
+	},
+}
+
+func TestParseError_Error(t *testing.T) {
+	for _, v := range parseErrorTests {
+		if v.Error.Error() != v.Expect {
+			t.Errorf("%s: error = %q, want %q", v.Name, v.Error.Error(), v.Expect)
+		}
+	}
+}
+
+func TestParseError_Unwrap(t *testing.T) {
+	lerr := Limits{MaxRecords: 2}.NewLimitError(RecordsLimit, 3)
+	var err error = &ParseError{Kind: LimitExceededError, Line: 3, Message: lerr.Message(), Err: lerr}
+
+	var result *LimitError
+	if !errors.As(err, &result) || result != lerr {
+		t.Errorf("limit error = %v, want %v", result, lerr)
+	}
+}
@@ -0,0 +1,658 @@
+package fixedlen
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mithrandie/go-text"
+)
+
+type Usage int
+
+const (
+	DisplayUsage Usage = iota
+	BinaryUsage
+	PackedDecimalUsage
+)
+
+var UsageLiteral = map[Usage]string{
+	DisplayUsage:       "DISPLAY",
+	BinaryUsage:        "BINARY",
+	PackedDecimalUsage: "PACKED-DECIMAL",
+}
+
+func (u Usage) String() string {
+	return UsageLiteral[u]
+}
+
+// CopybookItem is a data description entry of a COBOL copybook.
+type CopybookItem struct {
+	Level int
+	// Name is empty for FILLER items.
+	Name      string
+	Picture   string
+	Usage     Usage
+	Occurs    int
+	Redefines string
+
+	SignLeading  bool
+	SignSeparate bool
+
+	Children []*CopybookItem
+
+	usageSet bool
+}
+
+func (item *CopybookItem) IsGroup() bool {
+	return 0 < len(item.Children)
+}
+
+// Copybook is a COBOL copybook consisting of records defined by level 01 and 77 items.
+type Copybook struct {
+	Records []*CopybookItem
+}
+
+// ParseCopybook parses data description entries of a COBOL copybook.
+//
+// Both fixed and free source formats are accepted. In the fixed format, the sequence area,
+// the indicator area and columns after 72 are ignored.
+// Level 66 and 88 entries are skipped. VALUE, SYNCHRONIZED, JUSTIFIED and BLANK WHEN ZERO clauses are ignored.
+func ParseCopybook(r io.Reader) (*Copybook, error) {
+	src, err := copybookSource(r)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := splitCopybookEntries(src)
+	if err != nil {
+		return nil, err
+	}
+
+	cb := &Copybook{}
+	stack := make([]*CopybookItem, 0, 8)
+	for _, tokens := range entries {
+		item, err := parseCopybookEntry(tokens)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			continue
+		}
+
+		if item.Level == 1 || item.Level == 77 {
+			cb.Records = append(cb.Records, item)
+			stack = append(stack[:0], item)
+			continue
+		}
+
+		for 0 < len(stack) && item.Level <= stack[len(stack)-1].Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) < 1 {
+			return nil, errors.New(fmt.Sprintf("level %02d item %s does not belong to any record", item.Level, item.displayName()))
+		}
+
+		parent := stack[len(stack)-1]
+		if parent.Level == 77 || 0 < len(parent.Picture) {
+			return nil, errors.New(fmt.Sprintf("elementary item %s cannot have subordinate items", parent.displayName()))
+		}
+		if !item.usageSet {
+			item.Usage = parent.Usage
+		}
+		parent.Children = append(parent.Children, item)
+		stack = append(stack, item)
+	}
+
+	if len(cb.Records) < 1 {
+		return nil, errors.New("copybook has no records")
+	}
+	return cb, nil
+}
+
+// Schema returns the schema of the record. The first record is used if name is empty.
+//
+// Elementary items become fields. Items in OCCURS clauses are repeated with subscripts such as "AMOUNT(1)".
+// Items in OCCURS DEPENDING ON clauses are laid out at the maximum number of occurrences, so they are read
+// correctly only at the end of records framed by RDWFraming or LengthPrefixFraming, where absent occurrences are NULL.
+// Items redefining other items are not included since fields cannot overlap, and the storage
+// beyond the redefined items is included as FILLER.
+// Names of FILLER items are numbered, and duplicate names are qualified by the names of the groups
+// such as "CODE OF CUSTOMER".
+func (c *Copybook) Schema(name string) (*Schema, error) {
+	var record *CopybookItem
+	for _, rec := range c.Records {
+		if len(name) < 1 || strings.EqualFold(rec.Name, name) {
+			record = rec
+			break
+		}
+	}
+	if record == nil {
+		return nil, errors.New(fmt.Sprintf("record %s is not found", name))
+	}
+
+	l := &copybookLayout{
+		names: make(map[string]bool),
+	}
+	if _, err := l.layout(record, nil, 0, ""); err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{Fields: l.fields}
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+type copybookLayout struct {
+	fields  []SchemaField
+	names   map[string]bool
+	fillers int
+}
+
+func (l *copybookLayout) layout(item *CopybookItem, parent *CopybookItem, pos int, subscript string) (int, error) {
+	occurs := 1
+	if 0 < item.Occurs {
+		occurs = item.Occurs
+	}
+
+	for i := 1; i <= occurs; i++ {
+		sub := subscript
+		if 0 < item.Occurs {
+			if 0 < len(sub) {
+				sub = sub + ","
+			}
+			sub = sub + strconv.Itoa(i)
+		}
+
+		var err error
+		if item.IsGroup() {
+			pos, err = l.layoutGroup(item, pos, sub)
+		} else {
+			pos, err = l.layoutElementary(item, parent, pos, sub)
+		}
+		if err != nil {
+			return pos, err
+		}
+	}
+	return pos, nil
+}
+
+func (l *copybookLayout) layoutGroup(item *CopybookItem, pos int, subscript string) (int, error) {
+	type extent struct {
+		start int
+		end   int
+	}
+	extents := make(map[string]extent, len(item.Children))
+
+	end := pos
+	for _, child := range item.Children {
+		if 0 < len(child.Redefines) {
+			redefined, ok := extents[strings.ToUpper(child.Redefines)]
+			if !ok {
+				return end, errors.New(fmt.Sprintf("item %s redefines unknown item %s", child.displayName(), child.Redefines))
+			}
+			size, err := child.size()
+			if err != nil {
+				return end, err
+			}
+			if redefined.end < redefined.start+size && end < redefined.start+size {
+				l.addFiller(end, redefined.start+size, subscript)
+				end = redefined.start + size
+			}
+			continue
+		}
+
+		start := end
+		next, err := l.layout(child, item, start, subscript)
+		if err != nil {
+			return end, err
+		}
+		if 0 < len(child.Name) {
+			extents[strings.ToUpper(child.Name)] = extent{start: start, end: next}
+		}
+		end = next
+	}
+	return end, nil
+}
+
+func (l *copybookLayout) layoutElementary(item *CopybookItem, parent *CopybookItem, pos int, subscript string) (int, error) {
+	pic, err := parsePicture(item.Picture)
+	if err != nil {
+		return pos, errors.New(fmt.Sprintf("item %s: %s", item.displayName(), err.Error()))
+	}
+	size, err := item.elementarySize(pic)
+	if err != nil {
+		return pos, err
+	}
+
+	f := SchemaField{
+		Start:     pos,
+		End:       pos + size,
+		Alignment: text.LeftAligned,
+		Type:      text.StringType,
+	}
+
+	if pic.numeric {
+		f.Alignment = text.RightAligned
+		f.Scale = pic.scale
+		f.Type = text.IntegerType
+		if 0 < pic.scale {
+			f.Type = text.FloatType
+		}
+
+		switch item.Usage {
+		case BinaryUsage:
+			f.Format = BinaryFormat
+			f.Signed = pic.signed
+		case PackedDecimalUsage:
+			f.Format = PackedDecimalFormat
+		default:
+			f.Format = ZonedDecimalFormat
+			f.SignLeading = pic.signed && item.SignLeading
+			f.SignSeparate = pic.signed && item.SignSeparate
+		}
+	}
+
+	if len(item.Name) < 1 {
+		l.fillers++
+		f.Name = "FILLER-" + strconv.Itoa(l.fillers)
+	} else {
+		f.Name = item.Name
+		if l.names[f.Name] && parent != nil && 0 < len(parent.Name) {
+			f.Name = f.Name + " OF " + parent.Name
+		}
+	}
+	if 0 < len(subscript) {
+		f.Name = f.Name + "(" + subscript + ")"
+	}
+	l.names[f.Name] = true
+
+	l.fields = append(l.fields, f)
+	return f.End, nil
+}
+
+func (l *copybookLayout) addFiller(start int, end int, subscript string) {
+	l.fillers++
+	name := "FILLER-" + strconv.Itoa(l.fillers)
+	if 0 < len(subscript) {
+		name = name + "(" + subscript + ")"
+	}
+	l.fields = append(l.fields, SchemaField{
+		Name:      name,
+		Start:     start,
+		End:       end,
+		Alignment: text.LeftAligned,
+		Type:      text.StringType,
+	})
+}
+
+func (item *CopybookItem) displayName() string {
+	if len(item.Name) < 1 {
+		return "FILLER"
+	}
+	return item.Name
+}
+
+// size returns the storage size of the item including occurrences.
+func (item *CopybookItem) size() (int, error) {
+	l := &copybookLayout{
+		names: make(map[string]bool),
+	}
+	return l.layout(item, nil, 0, "")
+}
+
+func (item *CopybookItem) elementarySize(pic picture) (int, error) {
+	if !pic.numeric {
+		if item.Usage != DisplayUsage {
+			return 0, errors.New(fmt.Sprintf("item %s: %s usage requires a numeric picture", item.displayName(), item.Usage))
+		}
+		return pic.length, nil
+	}
+
+	switch item.Usage {
+	case BinaryUsage:
+		switch {
+		case pic.digits <= 4:
+			return 2, nil
+		case pic.digits <= 9:
+			return 4, nil
+		case pic.digits <= 18:
+			return 8, nil
+		}
+		return 0, errors.New(fmt.Sprintf("item %s: too many digits for binary: %d", item.displayName(), pic.digits))
+	case PackedDecimalUsage:
+		return pic.digits/2 + 1, nil
+	}
+
+	if pic.signed && item.SignSeparate {
+		return pic.length + 1, nil
+	}
+	return pic.length, nil
+}
+
+type picture struct {
+	numeric bool
+	signed  bool
+	digits  int
+	scale   int
+	length  int
+}
+
+func parsePicture(s string) (picture, error) {
+	pic := picture{}
+	if len(s) < 1 {
+		return pic, errors.New("picture is not specified")
+	}
+
+	expanded := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '(' {
+			end := strings.IndexByte(s[i:], ')')
+			if end < 0 || len(expanded) < 1 {
+				return pic, errors.New(fmt.Sprintf("invalid picture: %q", s))
+			}
+			n, err := strconv.Atoi(s[i+1 : i+end])
+			if err != nil || n < 1 {
+				return pic, errors.New(fmt.Sprintf("invalid picture: %q", s))
+			}
+			for j := 1; j < n; j++ {
+				expanded = append(expanded, expanded[len(expanded)-1])
+			}
+			i = i + end
+			continue
+		}
+		if 'a' <= c && c <= 'z' {
+			c = c - 'a' + 'A'
+		}
+		expanded = append(expanded, c)
+	}
+
+	pic.numeric = true
+	alphanumeric := true
+	afterPoint := false
+	for _, c := range expanded {
+		switch c {
+		case '9':
+			pic.digits++
+			if afterPoint {
+				pic.scale++
+			}
+		case 'S':
+			pic.signed = true
+		case 'V':
+			afterPoint = true
+		case 'P':
+			return pic, errors.New(fmt.Sprintf("scaling position in picture is not supported: %q", s))
+		case 'X', 'A':
+			pic.numeric = false
+		default:
+			pic.numeric = false
+			alphanumeric = false
+		}
+
+		if c != 'S' && c != 'V' {
+			pic.length++
+		}
+	}
+
+	if !pic.numeric {
+		pic.signed = false
+		pic.digits = 0
+		pic.scale = 0
+		if alphanumeric {
+			pic.length = len(expanded)
+		}
+	}
+	if pic.length < 1 {
+		return pic, errors.New(fmt.Sprintf("invalid picture: %q", s))
+	}
+	return pic, nil
+}
+
+func copybookSource(r io.Reader) (string, error) {
+	var buf strings.Builder
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if isFixedFormatLine(line) {
+			switch line[6] {
+			case '*', '/', 'D', 'd':
+				continue
+			}
+			line = line[7:]
+			if 65 < len(line) {
+				line = line[:65]
+			}
+		}
+
+		if i := strings.Index(line, "*>"); -1 < i {
+			line = line[:i]
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+	return buf.String(), scanner.Err()
+}
+
+// isFixedFormatLine returns true if the line has the sequence area consisting of digits or spaces
+// followed by an indicator.
+func isFixedFormatLine(line string) bool {
+	if len(line) < 7 {
+		return false
+	}
+	seq := line[:6]
+	if strings.Trim(seq, " ") != "" && strings.Trim(seq, "0123456789") != "" {
+		return false
+	}
+	switch line[6] {
+	case ' ', '*', '/', '-', 'D', 'd':
+		return true
+	}
+	return false
+}
+
+// splitCopybookEntries splits the source into tokens of entries terminated by periods.
+func splitCopybookEntries(src string) ([][]string, error) {
+	entries := make([][]string, 0, 32)
+	tokens := make([]string, 0, 8)
+
+	isSpace := func(c byte) bool {
+		return c == ' ' || c == '\t' || c == '\n' || c == ',' || c == ';'
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+
+		switch {
+		case c == ',' || c == ';':
+			if i+1 < len(src) && !isSpace(src[i+1]) {
+				break
+			}
+			i++
+			continue
+		case isSpace(c):
+			i++
+			continue
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, errors.New(fmt.Sprintf("unterminated literal: %s", strings.TrimSpace(src[i:])))
+			}
+			tokens = append(tokens, src[i:i+end+2])
+			i = i + end + 2
+			continue
+		}
+
+		start := i
+		for i < len(src) && !isSpace(src[i]) {
+			if src[i] == '.' && (i+1 == len(src) || isSpace(src[i+1])) {
+				break
+			}
+			i++
+		}
+		if start < i {
+			tokens = append(tokens, src[start:i])
+		}
+
+		if i < len(src) && src[i] == '.' {
+			i++
+			if 0 < len(tokens) {
+				entries = append(entries, tokens)
+				tokens = make([]string, 0, 8)
+			}
+		}
+	}
+
+	if 0 < len(tokens) {
+		return nil, errors.New(fmt.Sprintf("entry is not terminated by a period: %s", strings.Join(tokens, " ")))
+	}
+	return entries, nil
+}
+
+var copybookClauses = map[string]bool{
+	"PIC": true, "PICTURE": true, "USAGE": true, "OCCURS": true, "REDEFINES": true,
+	"VALUE": true, "VALUES": true, "SIGN": true, "LEADING": true, "TRAILING": true,
+	"SYNC": true, "SYNCHRONIZED": true, "JUST": true, "JUSTIFIED": true, "BLANK": true,
+	"GLOBAL": true, "EXTERNAL": true,
+}
+
+func parseCopybookEntry(tokens []string) (*CopybookItem, error) {
+	level, err := strconv.Atoi(tokens[0])
+	if err != nil || level < 1 || (49 < level && level != 66 && level != 77 && level != 88) {
+		return nil, errors.New(fmt.Sprintf("invalid level number: %s", tokens[0]))
+	}
+	if level == 66 || level == 88 {
+		return nil, nil
+	}
+
+	item := &CopybookItem{Level: level}
+
+	pos := 1
+	if pos < len(tokens) {
+		word := strings.ToUpper(tokens[pos])
+		if !copybookClauses[word] && usageOf(word) < 0 {
+			if word != "FILLER" {
+				item.Name = tokens[pos]
+			}
+			pos++
+		}
+	}
+
+	next := func() (string, bool) {
+		if len(tokens) <= pos {
+			return "", false
+		}
+		t := tokens[pos]
+		pos++
+		return t, true
+	}
+	skipWord := func(words ...string) {
+		if pos < len(tokens) {
+			for _, w := range words {
+				if strings.EqualFold(tokens[pos], w) {
+					pos++
+					return
+				}
+			}
+		}
+	}
+	skipUntilClause := func() {
+		for pos < len(tokens) && !copybookClauses[strings.ToUpper(tokens[pos])] && usageOf(strings.ToUpper(tokens[pos])) < 0 {
+			pos++
+		}
+	}
+
+	for pos < len(tokens) {
+		word, _ := next()
+		word = strings.ToUpper(word)
+
+		switch word {
+		case "PIC", "PICTURE":
+			skipWord("IS")
+			if item.Picture, _ = next(); len(item.Picture) < 1 {
+				return nil, errors.New(fmt.Sprintf("item %s: picture is not specified", item.displayName()))
+			}
+		case "USAGE":
+			skipWord("IS")
+			u, _ := next()
+			usage := usageOf(strings.ToUpper(u))
+			if usage < 0 {
+				return nil, errors.New(fmt.Sprintf("item %s: unsupported usage: %s", item.displayName(), u))
+			}
+			item.Usage = usage
+			item.usageSet = true
+		case "OCCURS":
+			n, _ := next()
+			if item.Occurs, err = strconv.Atoi(n); err != nil || item.Occurs < 1 {
+				return nil, errors.New(fmt.Sprintf("item %s: invalid occurs: %s", item.displayName(), n))
+			}
+			// Variable occurrences are laid out at the maximum.
+			skipWord("TO")
+			if pos < len(tokens) && strings.EqualFold(tokens[pos-1], "TO") {
+				m, _ := next()
+				if item.Occurs, err = strconv.Atoi(m); err != nil || item.Occurs < 1 {
+					return nil, errors.New(fmt.Sprintf("item %s: invalid occurs: %s", item.displayName(), m))
+				}
+			}
+			skipWord("TIMES")
+			skipUntilClause()
+		case "REDEFINES":
+			if item.Redefines, _ = next(); len(item.Redefines) < 1 {
+				return nil, errors.New(fmt.Sprintf("item %s: redefined item is not specified", item.displayName()))
+			}
+		case "SIGN", "LEADING", "TRAILING":
+			if word == "SIGN" {
+				skipWord("IS")
+				word, _ = next()
+				word = strings.ToUpper(word)
+			}
+			switch word {
+			case "LEADING":
+				item.SignLeading = true
+			case "TRAILING":
+			default:
+				return nil, errors.New(fmt.Sprintf("item %s: invalid sign clause", item.displayName()))
+			}
+			if pos < len(tokens) && strings.EqualFold(tokens[pos], "SEPARATE") {
+				item.SignSeparate = true
+				pos++
+				skipWord("CHARACTER")
+			}
+		case "VALUE", "VALUES", "SYNC", "SYNCHRONIZED", "JUST", "JUSTIFIED", "BLANK", "GLOBAL", "EXTERNAL":
+			skipUntilClause()
+		default:
+			usage := usageOf(word)
+			if usage < 0 {
+				if strings.HasPrefix(word, "COMP") || word == "INDEX" || word == "POINTER" || word == "NATIONAL" {
+					return nil, errors.New(fmt.Sprintf("item %s: unsupported usage: %s", item.displayName(), word))
+				}
+				return nil, errors.New(fmt.Sprintf("item %s: unknown clause: %s", item.displayName(), word))
+			}
+			item.Usage = usage
+			item.usageSet = true
+		}
+	}
+
+	return item, nil
+}
+
+func usageOf(word string) Usage {
+	switch word {
+	case "DISPLAY":
+		return DisplayUsage
+	case "BINARY", "COMP", "COMPUTATIONAL", "COMP-4", "COMPUTATIONAL-4", "COMP-5", "COMPUTATIONAL-5":
+		return BinaryUsage
+	case "PACKED-DECIMAL", "COMP-3", "COMPUTATIONAL-3":
+		return PackedDecimalUsage
+	}
+	return -1
+}
@@ -0,0 +1,197 @@
+package fixedlen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+var copybookSchemaTests = []struct {
+	Name     string
+	Copybook string
+	Record   string
+	Expect   []SchemaField
+	Error    string
+}{
+	{
+		Name: "Fixed Format",
+		Copybook: "" +
+			"000100 01  CUSTOMER-RECORD.                                             CUST0001\n" +
+			"000200     05  CUST-ID          PIC 9(5).                               CUST0002\n" +
+			"000300*    05  UNUSED           PIC X(3).\n" +
+			"000400     05  CUST-NAME        PIC X(10).\n" +
+			"000500     05  BALANCE          PIC S9(5)V99 COMP-3.\n" +
+			"000600     05  STATUS           PIC X.\n" +
+			"000700         88  ACTIVE       VALUE 'A'.\n" +
+			"000800     05  QTY              PIC S9(3)\n" +
+			"000900                          SIGN IS LEADING SEPARATE CHARACTER.\n",
+		Expect: []SchemaField{
+			{Name: "CUST-ID", Start: 0, End: 5, Length: 5, Alignment: text.RightAligned, Type: text.IntegerType, Format: ZonedDecimalFormat},
+			{Name: "CUST-NAME", Start: 5, End: 15, Length: 10, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "BALANCE", Start: 15, End: 19, Length: 4, Alignment: text.RightAligned, Type: text.FloatType, Format: PackedDecimalFormat, Scale: 2},
+			{Name: "STATUS", Start: 19, End: 20, Length: 1, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "QTY", Start: 20, End: 24, Length: 4, Alignment: text.RightAligned, Type: text.IntegerType, Format: ZonedDecimalFormat, SignLeading: true, SignSeparate: true},
+		},
+	},
+	{
+		Name: "Free Format",
+		Copybook: "" +
+			"*> comment\n" +
+			"01 REC.\n" +
+			"  05 CODE PIC XX. *> inline comment\n" +
+			"  05 FILLER PIC X(3).\n" +
+			"  05 PIC X.\n" +
+			"  05 COUNTER PIC 9(9) USAGE IS BINARY.\n",
+		Expect: []SchemaField{
+			{Name: "CODE", Start: 0, End: 2, Length: 2, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "FILLER-1", Start: 2, End: 5, Length: 3, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "FILLER-2", Start: 5, End: 6, Length: 1, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "COUNTER", Start: 6, End: 10, Length: 4, Alignment: text.RightAligned, Type: text.IntegerType, Format: BinaryFormat},
+		},
+	},
+	{
+		Name: "Occurs",
+		Copybook: "" +
+			"01 REC.\n" +
+			"  05 FLAG PIC X OCCURS 2 TIMES.\n" +
+			"  05 ROW OCCURS 2.\n" +
+			"    10 CELL PIC S9(4) COMP OCCURS 1 TO 2 DEPENDING ON CELLS.\n",
+		Expect: []SchemaField{
+			{Name: "FLAG(1)", Start: 0, End: 1, Length: 1, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "FLAG(2)", Start: 1, End: 2, Length: 1, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "CELL(1,1)", Start: 2, End: 4, Length: 2, Alignment: text.RightAligned, Type: text.IntegerType, Format: BinaryFormat, Signed: true},
+			{Name: "CELL(1,2)", Start: 4, End: 6, Length: 2, Alignment: text.RightAligned, Type: text.IntegerType, Format: BinaryFormat, Signed: true},
+			{Name: "CELL(2,1)", Start: 6, End: 8, Length: 2, Alignment: text.RightAligned, Type: text.IntegerType, Format: BinaryFormat, Signed: true},
+			{Name: "CELL(2,2)", Start: 8, End: 10, Length: 2, Alignment: text.RightAligned, Type: text.IntegerType, Format: BinaryFormat, Signed: true},
+		},
+	},
+	{
+		Name: "Redefines",
+		Copybook: "" +
+			"01 REC.\n" +
+			"  05 RAW-DATE PIC X(6).\n" +
+			"  05 DATE-PARTS REDEFINES RAW-DATE.\n" +
+			"    10 YEAR PIC 9(4).\n" +
+			"    10 MONTH PIC 99.\n" +
+			"  05 LONG-DATE REDEFINES RAW-DATE PIC X(8).\n" +
+			"  05 CODE PIC X.\n",
+		Expect: []SchemaField{
+			{Name: "RAW-DATE", Start: 0, End: 6, Length: 6, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "FILLER-1", Start: 6, End: 8, Length: 2, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "CODE", Start: 8, End: 9, Length: 1, Alignment: text.LeftAligned, Type: text.StringType},
+		},
+	},
+	{
+		Name: "Group Usage and Duplicate Names",
+		Copybook: "" +
+			"01 REC.\n" +
+			"  05 CUSTOMER COMP-3.\n" +
+			"    10 CODE PIC 9(3).\n" +
+			"  05 SUPPLIER.\n" +
+			"    10 CODE PIC 9(3).\n",
+		Expect: []SchemaField{
+			{Name: "CODE", Start: 0, End: 2, Length: 2, Alignment: text.RightAligned, Type: text.IntegerType, Format: PackedDecimalFormat},
+			{Name: "CODE OF SUPPLIER", Start: 2, End: 5, Length: 3, Alignment: text.RightAligned, Type: text.IntegerType, Format: ZonedDecimalFormat},
+		},
+	},
+	{
+		Name: "Select Record",
+		Copybook: "" +
+			"01 HEADER-REC.\n" +
+			"  05 KIND PIC X.\n" +
+			"01 TRAILER-REC.\n" +
+			"  05 KIND PIC X.\n" +
+			"  05 TOTAL PIC 9(7)V99 PACKED-DECIMAL VALUE ZERO.\n",
+		Record: "trailer-rec",
+		Expect: []SchemaField{
+			{Name: "KIND", Start: 0, End: 1, Length: 1, Alignment: text.LeftAligned, Type: text.StringType},
+			{Name: "TOTAL", Start: 1, End: 6, Length: 5, Alignment: text.RightAligned, Type: text.FloatType, Format: PackedDecimalFormat, Scale: 2},
+		},
+	},
+	{
+		Name:     "Record Not Found",
+		Copybook: "01 REC.\n  05 KIND PIC X.\n",
+		Record:   "OTHER",
+		Error:    "record OTHER is not found",
+	},
+	{
+		Name:     "Unsupported Usage",
+		Copybook: "01 REC.\n  05 RATE COMP-2.\n",
+		Error:    "item RATE: unsupported usage: COMP-2",
+	},
+	{
+		Name:     "Unknown Clause",
+		Copybook: "01 REC.\n  05 KIND PIC X RENAMES.\n",
+		Error:    "item KIND: unknown clause: RENAMES",
+	},
+	{
+		Name:     "Not Terminated",
+		Copybook: "01 REC.\n  05 KIND PIC X\n",
+		Error:    "entry is not terminated by a period: 05 KIND PIC X",
+	},
+	{
+		Name:     "Binary Alphanumeric",
+		Copybook: "01 REC.\n  05 KIND PIC X COMP.\n",
+		Error:    "item KIND: BINARY usage requires a numeric picture",
+	},
+	{
+		Name:     "Unknown Redefined Item",
+		Copybook: "01 REC.\n  05 A PIC X.\n  05 B REDEFINES C PIC X.\n",
+		Error:    "item B redefines unknown item C",
+	},
+}
+
+func TestCopybook_Schema(t *testing.T) {
+	for _, v := range copybookSchemaTests {
+		cb, err := ParseCopybook(strings.NewReader(v.Copybook))
+		var schema *Schema
+		if err == nil {
+			schema, err = cb.Schema(v.Record)
+		}
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(schema.Fields, v.Expect) {
+			t.Errorf("%s: fields = %#v, want %#v", v.Name, schema.Fields, v.Expect)
+		}
+	}
+}
+
+func TestParseCopybook(t *testing.T) {
+	cb, err := ParseCopybook(strings.NewReader("" +
+		"01 REC.\n" +
+		"  05 A PIC X(2).\n" +
+		"  05 B REDEFINES A PIC 99.\n" +
+		"77 FLAG PIC X VALUE 'Y'.\n"))
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	expect := &Copybook{
+		Records: []*CopybookItem{
+			{
+				Level: 1,
+				Name:  "REC",
+				Children: []*CopybookItem{
+					{Level: 5, Name: "A", Picture: "X(2)"},
+					{Level: 5, Name: "B", Picture: "99", Redefines: "A"},
+				},
+			},
+			{Level: 77, Name: "FLAG", Picture: "X"},
+		},
+	}
+	if !reflect.DeepEqual(cb, expect) {
+		t.Errorf("result = %#v, want %#v", cb, expect)
+	}
+}
@@ -0,0 +1,190 @@
+package fixedlen
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// DecodeZonedDecimal converts a zoned decimal into a decimal string.
+//
+// Digits are accepted in both ASCII and EBCDIC. The sign is overpunched in the zone of
+// the last byte, or the first byte if signLeading is true, unless signSeparate is true.
+// Overpunched signs are accepted in the EBCDIC form, the ASCII translation of the EBCDIC form
+// such as "{", "A" to "I", "}" and "J" to "R", and the ASCII form using 0x70 to 0x79 for
+// negative digits.
+func DecodeZonedDecimal(b []byte, scale int, signLeading bool, signSeparate bool) (string, error) {
+	if len(b) < 1 {
+		return "", errors.New("empty zoned decimal")
+	}
+
+	negative := false
+	digits := make([]byte, 0, len(b))
+
+	signPos := len(b) - 1
+	if signLeading {
+		signPos = 0
+	}
+
+	for i, c := range b {
+		if i == signPos {
+			if signSeparate {
+				switch c {
+				case '+', 0x4e:
+				case '-', 0x60:
+					negative = true
+				default:
+					return "", errors.New(fmt.Sprintf("invalid sign in zoned decimal: %#x", c))
+				}
+				continue
+			}
+
+			d, neg, ok := overpunchedDigit(c)
+			if !ok {
+				return "", errors.New(fmt.Sprintf("invalid digit in zoned decimal: %#x", c))
+			}
+			negative = neg
+			digits = append(digits, d)
+			continue
+		}
+
+		switch {
+		case '0' <= c && c <= '9':
+			digits = append(digits, c)
+		case 0xf0 <= c && c <= 0xf9:
+			digits = append(digits, '0'+(c&0x0f))
+		default:
+			return "", errors.New(fmt.Sprintf("invalid digit in zoned decimal: %#x", c))
+		}
+	}
+
+	return formatDecimal(negative, digits, scale), nil
+}
+
+func overpunchedDigit(c byte) (byte, bool, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c, false, true
+	case 0x70 <= c && c <= 0x79:
+		return '0' + (c & 0x0f), true, true
+	case c == '{':
+		return '0', false, true
+	case 'A' <= c && c <= 'I':
+		return '1' + (c - 'A'), false, true
+	case c == '}':
+		return '0', true, true
+	case 'J' <= c && c <= 'R':
+		return '1' + (c - 'J'), true, true
+	case c&0x0f <= 9:
+		switch c >> 4 {
+		case 0xa, 0xc, 0xe, 0xf:
+			return '0' + (c & 0x0f), false, true
+		case 0xb, 0xd:
+			return '0' + (c & 0x0f), true, true
+		}
+	}
+	return 0, false, false
+}
+
+// DecodePackedDecimal converts a packed decimal (COMP-3) into a decimal string.
+// Sign nibbles 0xB and 0xD are negative, and 0xA, 0xC, 0xE and 0xF are positive.
+func DecodePackedDecimal(b []byte, scale int) (string, error) {
+	if len(b) < 1 {
+		return "", errors.New("empty packed decimal")
+	}
+
+	digits := make([]byte, 0, len(b)*2-1)
+	for i, c := range b {
+		hi, lo := c>>4, c&0x0f
+		if 9 < hi {
+			return "", errors.New(fmt.Sprintf("invalid digit in packed decimal: %#x", c))
+		}
+		digits = append(digits, '0'+hi)
+
+		if i < len(b)-1 {
+			if 9 < lo {
+				return "", errors.New(fmt.Sprintf("invalid digit in packed decimal: %#x", c))
+			}
+			digits = append(digits, '0'+lo)
+		} else if lo < 0x0a {
+			return "", errors.New(fmt.Sprintf("invalid sign in packed decimal: %#x", c))
+		}
+	}
+
+	lastNibble := b[len(b)-1] & 0x0f
+	negative := lastNibble == 0x0b || lastNibble == 0x0d
+	return formatDecimal(negative, digits, scale), nil
+}
+
+// DecodeBinaryDecimal converts a big-endian binary integer (COMP, COMP-4 and COMP-5) of 1, 2, 4 or 8 bytes
+// into a decimal string.
+func DecodeBinaryDecimal(b []byte, signed bool, scale int) (string, error) {
+	var u uint64
+	switch len(b) {
+	case 1:
+		u = uint64(b[0])
+		if signed {
+			u = uint64(int64(int8(b[0])))
+		}
+	case 2:
+		u = uint64(binary.BigEndian.Uint16(b))
+		if signed {
+			u = uint64(int64(int16(u)))
+		}
+	case 4:
+		u = uint64(binary.BigEndian.Uint32(b))
+		if signed {
+			u = uint64(int64(int32(u)))
+		}
+	case 8:
+		u = binary.BigEndian.Uint64(b)
+	default:
+		return "", errors.New(fmt.Sprintf("invalid binary length: %d", len(b)))
+	}
+
+	var s string
+	if signed {
+		s = strconv.FormatInt(int64(u), 10)
+	} else {
+		s = strconv.FormatUint(u, 10)
+	}
+
+	negative := 0 < len(s) && s[0] == '-'
+	if negative {
+		s = s[1:]
+	}
+	return formatDecimal(negative, []byte(s), scale), nil
+}
+
+// formatDecimal formats digits with the implied decimal point before the last scale digits.
+func formatDecimal(negative bool, digits []byte, scale int) string {
+	for len(digits) <= scale {
+		digits = append([]byte{'0'}, digits...)
+	}
+
+	intPart := digits[:len(digits)-scale]
+	for 1 < len(intPart) && intPart[0] == '0' {
+		intPart = intPart[1:]
+	}
+
+	buf := make([]byte, 0, len(digits)+2)
+	if negative && !isZero(digits) {
+		buf = append(buf, '-')
+	}
+	buf = append(buf, intPart...)
+	if 0 < scale {
+		buf = append(buf, '.')
+		buf = append(buf, digits[len(digits)-scale:]...)
+	}
+	return string(buf)
+}
+
+func isZero(digits []byte) bool {
+	for _, d := range digits {
+		if d != '0' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,226 @@
+package fixedlen
+
+import (
+	"testing"
+)
+
+var decodeZonedDecimalTests = []struct {
+	Name         string
+	Input        []byte
+	Scale        int
+	SignLeading  bool
+	SignSeparate bool
+	Expect       string
+	Error        string
+}{
+	{
+		Name:   "Unsigned",
+		Input:  []byte("00123"),
+		Expect: "123",
+	},
+	{
+		Name:   "Scale",
+		Input:  []byte("00123"),
+		Scale:  2,
+		Expect: "1.23",
+	},
+	{
+		Name:   "Scale Larger Than Digits",
+		Input:  []byte("5"),
+		Scale:  3,
+		Expect: "0.005",
+	},
+	{
+		Name:   "ASCII Overpunch Positive",
+		Input:  []byte("0012C"),
+		Expect: "123",
+	},
+	{
+		Name:   "ASCII Overpunch Negative",
+		Input:  []byte("0012L"),
+		Scale:  1,
+		Expect: "-12.3",
+	},
+	{
+		Name:   "ASCII Overpunch Negative Zero",
+		Input:  []byte("000}"),
+		Expect: "0",
+	},
+	{
+		Name:   "ASCII Negative Zone",
+		Input:  []byte{'1', '2', 0x73},
+		Expect: "-123",
+	},
+	{
+		Name:   "EBCDIC",
+		Input:  []byte{0xf1, 0xf2, 0xd3},
+		Expect: "-123",
+	},
+	{
+		Name:        "Sign Leading",
+		Input:       []byte{0xd1, 0xf2, 0xf3},
+		SignLeading: true,
+		Expect:      "-123",
+	},
+	{
+		Name:         "Sign Separate Trailing",
+		Input:        []byte("123-"),
+		SignSeparate: true,
+		Expect:       "-123",
+	},
+	{
+		Name:         "Sign Separate Leading",
+		Input:        []byte("+123"),
+		SignLeading:  true,
+		SignSeparate: true,
+		Expect:       "123",
+	},
+	{
+		Name:         "Invalid Sign",
+		Input:        []byte("123*"),
+		SignSeparate: true,
+		Error:        "invalid sign in zoned decimal: 0x2a",
+	},
+	{
+		Name:  "Invalid Digit",
+		Input: []byte("1a3"),
+		Error: "invalid digit in zoned decimal: 0x61",
+	},
+}
+
+func TestDecodeZonedDecimal(t *testing.T) {
+	for _, v := range decodeZonedDecimalTests {
+		result, err := DecodeZonedDecimal(v.Input, v.Scale, v.SignLeading, v.SignSeparate)
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if result != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, result, v.Expect)
+		}
+	}
+}
+
+var decodePackedDecimalTests = []struct {
+	Name   string
+	Input  []byte
+	Scale  int
+	Expect string
+	Error  string
+}{
+	{
+		Name:   "Positive",
+		Input:  []byte{0x12, 0x34, 0x5c},
+		Expect: "12345",
+	},
+	{
+		Name:   "Negative",
+		Input:  []byte{0x12, 0x34, 0x5d},
+		Scale:  2,
+		Expect: "-123.45",
+	},
+	{
+		Name:   "Unsigned",
+		Input:  []byte{0x00, 0x04, 0x2f},
+		Expect: "42",
+	},
+	{
+		Name:  "Invalid Digit",
+		Input: []byte{0x1a, 0x2c},
+		Error: "invalid digit in packed decimal: 0x1a",
+	},
+	{
+		Name:  "Invalid Sign",
+		Input: []byte{0x12, 0x34},
+		Error: "invalid sign in packed decimal: 0x34",
+	},
+}
+
+func TestDecodePackedDecimal(t *testing.T) {
+	for _, v := range decodePackedDecimalTests {
+		result, err := DecodePackedDecimal(v.Input, v.Scale)
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if result != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, result, v.Expect)
+		}
+	}
+}
+
+var decodeBinaryDecimalTests = []struct {
+	Name   string
+	Input  []byte
+	Signed bool
+	Scale  int
+	Expect string
+	Error  string
+}{
+	{
+		Name:   "Unsigned Halfword",
+		Input:  []byte{0xff, 0xfe},
+		Expect: "65534",
+	},
+	{
+		Name:   "Signed Halfword",
+		Input:  []byte{0xff, 0xfe},
+		Signed: true,
+		Expect: "-2",
+	},
+	{
+		Name:   "Signed Fullword with Scale",
+		Input:  []byte{0xff, 0xff, 0xff, 0x85},
+		Signed: true,
+		Scale:  2,
+		Expect: "-1.23",
+	},
+	{
+		Name:   "Doubleword",
+		Input:  []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00},
+		Signed: true,
+		Expect: "65536",
+	},
+	{
+		Name:  "Invalid Length",
+		Input: []byte{0x00, 0x00, 0x01},
+		Error: "invalid binary length: 3",
+	},
+}
+
+func TestDecodeBinaryDecimal(t *testing.T) {
+	for _, v := range decodeBinaryDecimalTests {
+		result, err := DecodeBinaryDecimal(v.Input, v.Signed, v.Scale)
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if result != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, result, v.Expect)
+		}
+	}
+}
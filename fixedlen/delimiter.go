@@ -3,7 +3,10 @@ package fixedlen
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 	"unicode"
 
 	"github.com/mithrandie/go-text"
@@ -133,14 +136,46 @@ func (t TableSpaces) CountColumnStatus(pos int) (inValue int, endOfValue int, in
 	return
 }
 
+// Boundary is a delimiter position with the reliability in the lines examined.
+type Boundary struct {
+	Position int
+	// Confidence is the ratio of the lines not having a value across the position
+	// to the lines reaching the position.
+	Confidence float64
+	// Conflicts is the number of lines having a value across the position.
+	Conflicts int
+}
+
+type DelimitResult struct {
+	Positions  DelimiterPositions
+	Boundaries []Boundary
+	// Lines is the number of non-empty lines examined.
+	Lines int
+	// ConflictingLines is the number of lines having a value across any of the positions.
+	ConflictingLines int
+}
+
 type Delimiter struct {
 	NoHeader bool
 	Encoding text.Encoding
 
+	// Unit is the unit of the positions returned by Delimit.
+	Unit Unit
+
+	// SampleLines is the maximum number of non-empty lines examined. All lines are examined if 0.
+	SampleLines int
+	// MinColumnGap is the minimum number of spaces separating columns.
+	// Shorter spaces are regarded as a part of values.
+	MinColumnGap int
+	// HeaderNames are the names of the columns in the header line.
+	// If specified, each position is searched between the names.
+	HeaderNames []string
+
 	reader *bufio.Reader
 
 	lineBuf         bytes.Buffer
 	spacesPerRecord int
+	header          string
 
 	tableSpaces TableSpaces
 	positions   DelimiterPositions
@@ -160,9 +195,24 @@ func NewDelimiter(r io.Reader, enc text.Encoding) (*Delimiter, error) {
 }
 
 func (d *Delimiter) Delimit() ([]int, error) {
+	result, err := d.Analyze()
+	if err != nil {
+		return nil, err
+	}
+	return result.Positions, nil
+}
+
+// Analyze returns the delimiter positions with the confidence of each position.
+// Reading stops after SampleLines lines.
+func (d *Delimiter) Analyze() (*DelimitResult, error) {
+	if 0 < len(d.HeaderNames) && d.NoHeader {
+		return nil, errors.New("header names require a header line")
+	}
+
 	d.tableSpaces = make(TableSpaces, 0, 100)
+	d.header = ""
 
-	for {
+	for d.SampleLines < 1 || len(d.tableSpaces) < d.SampleLines {
 		recordSpaces, err := d.searchSpacesInLine()
 		if err == io.EOF {
 			break
@@ -178,19 +228,117 @@ func (d *Delimiter) Delimit() ([]int, error) {
 
 	d.positions = make([]int, 0, d.spacesPerRecord)
 
-	linePos := 1
-	for {
-		nextEnd := d.tableSpaces.NextSpaceEnd(linePos)
-		if nextEnd == OutOfLine {
-			d.positions = append(d.positions, d.tableSpaces.TableLen())
-			break
+	if 0 < len(d.HeaderNames) {
+		if err := d.searchPositionsByHeader(); err != nil {
+			return nil, err
 		}
+	} else {
+		linePos := 1
+		for {
+			nextEnd := d.tableSpaces.NextSpaceEnd(linePos)
+			if nextEnd == OutOfLine {
+				d.positions = append(d.positions, d.tableSpaces.TableLen())
+				break
+			}
 
-		d.searchPosition(nextEnd)
-		linePos = nextEnd + 1
+			d.searchPosition(nextEnd)
+			linePos = nextEnd + 1
+		}
 	}
 
-	return d.positions, nil
+	return d.evaluate(), nil
+}
+
+// searchPositionsByHeader determines each position between the header names where the fewest lines
+// have a value across the position.
+func (d *Delimiter) searchPositionsByHeader() error {
+	type span struct {
+		start int
+		end   int
+	}
+	spans := make([]span, 0, len(d.HeaderNames))
+
+	idx := 0
+	for _, name := range d.HeaderNames {
+		i := strings.Index(d.header[idx:], name)
+		if len(name) < 1 || i < 0 {
+			return errors.New(fmt.Sprintf("header %q is not found", name))
+		}
+		start := d.Unit.Size(d.header[:idx+i], d.Encoding)
+		spans = append(spans, span{start: start, end: start + d.Unit.Size(name, d.Encoding)})
+		idx = idx + i + len(name)
+	}
+
+	for i := 0; i < len(spans)-1; i++ {
+		best := spans[i+1].start
+		bestConflicts := -1
+		for pos := spans[i+1].start; spans[i].end <= pos; pos-- {
+			if conflicts := d.countConflicts(pos); bestConflicts < 0 || conflicts < bestConflicts {
+				best = pos
+				bestConflicts = conflicts
+			}
+		}
+		d.positions = append(d.positions, best)
+	}
+
+	tableLen := d.tableSpaces.TableLen()
+	if tableLen < spans[len(spans)-1].end {
+		tableLen = spans[len(spans)-1].end
+	}
+	d.positions = append(d.positions, tableLen)
+	return nil
+}
+
+func (d *Delimiter) countConflicts(pos int) int {
+	n := 0
+	for _, rs := range d.tableSpaces {
+		if rs.Status(pos) == PositionInValue {
+			n++
+		}
+	}
+	return n
+}
+
+func (d *Delimiter) evaluate() *DelimitResult {
+	result := &DelimitResult{
+		Positions:  d.positions,
+		Boundaries: make([]Boundary, 0, len(d.positions)),
+		Lines:      len(d.tableSpaces),
+	}
+
+	conflicting := make([]bool, len(d.tableSpaces))
+	for i, pos := range d.positions {
+		b := Boundary{
+			Position:   pos,
+			Confidence: 1,
+		}
+
+		if i < len(d.positions)-1 {
+			reached := 0
+			for j, rs := range d.tableSpaces {
+				switch rs.Status(pos) {
+				case PositionOut:
+					continue
+				case PositionInValue:
+					b.Conflicts++
+					conflicting[j] = true
+				}
+				reached++
+			}
+			if 0 < reached {
+				b.Confidence = float64(reached-b.Conflicts) / float64(reached)
+			}
+		}
+
+		result.Boundaries = append(result.Boundaries, b)
+	}
+
+	for _, c := range conflicting {
+		if c {
+			result.ConflictingLines++
+		}
+	}
+	return result
 }
 
 func (d *Delimiter) searchPosition(end int) {
@@ -251,10 +399,16 @@ func (d *Delimiter) searchSpacesInLine() (RecordSpaces, error) {
 		}
 	}
 
+	if len(d.tableSpaces) < 1 {
+		d.header = d.lineBuf.String()
+	}
+
 	spaces := make(RecordSpaces, 0, d.spacesPerRecord)
 	linePos := 1
 	startPos := 1
 	inSpace := false
+	shifted := false
+	countShiftCodes := d.Unit.PositionUnit == ByteUnit && text.IsShiftEncoding(d.Encoding)
 	for {
 		c, _, err := d.lineBuf.ReadRune()
 		if err != nil {
@@ -275,7 +429,7 @@ func (d *Delimiter) searchSpacesInLine() (RecordSpaces, error) {
 		} else {
 			if inSpace {
 				inSpace = false
-				if 1 < startPos {
+				if 1 < startPos && d.MinColumnGap <= linePos-startPos {
 					spaces = append(spaces, Space{
 						Start: startPos,
 						End:   linePos - 1,
@@ -284,7 +438,16 @@ func (d *Delimiter) searchSpacesInLine() (RecordSpaces, error) {
 			}
 		}
 
-		linePos = linePos + text.RuneByteSize(c, d.Encoding)
+		size := d.Unit.RuneSize(c, d.Encoding)
+		if countShiftCodes {
+			if size < 2 {
+				shifted = false
+			} else if !shifted {
+				size = size + 2
+				shifted = true
+			}
+		}
+		linePos = linePos + size
 	}
 
 	if 1 < startPos {
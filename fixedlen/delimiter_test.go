@@ -75,6 +75,7 @@ var delimiterDelimitTests = []struct {
 	Input    string
 	NoHeader bool
 	Encoding text.Encoding
+	Unit     Unit
 	Expect   []int
 	Error    string
 }{
@@ -303,6 +304,15 @@ var delimiterDelimitTests = []struct {
 		Encoding: text.UTF8M,
 		Expect:   []int{3, 7, 11, 15},
 	},
+	{
+		Input: "" +
+			"日本 abc\n" +
+			"ab   cde\n",
+		NoHeader: true,
+		Encoding: text.UTF8,
+		Unit:     Unit{PositionUnit: WidthUnit},
+		Expect:   []int{4, 8},
+	},
 }
 
 func TestDelimiter_Delimit(t *testing.T) {
@@ -319,6 +329,7 @@ func TestDelimiter_Delimit(t *testing.T) {
 
 		d.NoHeader = v.NoHeader
 		d.Encoding = v.Encoding
+		d.Unit = v.Unit
 
 		result, err := d.Delimit()
 
@@ -339,3 +350,127 @@ func TestDelimiter_Delimit(t *testing.T) {
 		}
 	}
 }
+
+var delimiterAnalyzeTests = []struct {
+	Name         string
+	Input        string
+	NoHeader     bool
+	SampleLines  int
+	MinColumnGap int
+	HeaderNames  []string
+	Expect       *DelimitResult
+	Error        string
+}{
+	{
+		Name:     "Conflicting Line",
+		Input:    "aaa bbb ccc\naaa bbb ccc\naaa bbb ccc\naaa bbbbccc\n",
+		NoHeader: true,
+		Expect: &DelimitResult{
+			Positions: []int{3, 8, 11},
+			Boundaries: []Boundary{
+				{Position: 3, Confidence: 1},
+				{Position: 8, Confidence: 0.75, Conflicts: 1},
+				{Position: 11, Confidence: 1},
+			},
+			Lines:            4,
+			ConflictingLines: 1,
+		},
+	},
+	{
+		Name:        "Sample Lines",
+		Input:       "aaa bbb ccc\naaa bbb ccc\naaa bbb ccc\naaa bbbbccc\n",
+		NoHeader:    true,
+		SampleLines: 3,
+		Expect: &DelimitResult{
+			Positions: []int{3, 7, 11},
+			Boundaries: []Boundary{
+				{Position: 3, Confidence: 1},
+				{Position: 7, Confidence: 1},
+				{Position: 11, Confidence: 1},
+			},
+			Lines: 3,
+		},
+	},
+	{
+		Name:         "Minimum Column Gap",
+		Input:        "John Smith  25\nAnn Lee     31\n",
+		NoHeader:     true,
+		MinColumnGap: 2,
+		Expect: &DelimitResult{
+			Positions: []int{10, 14},
+			Boundaries: []Boundary{
+				{Position: 10, Confidence: 1},
+				{Position: 14, Confidence: 1},
+			},
+			Lines: 2,
+		},
+	},
+	{
+		Name:        "Header Names",
+		Input:       "name      amount\nabc          100\nabcdefgh       5\n",
+		HeaderNames: []string{"name", "amount"},
+		Expect: &DelimitResult{
+			Positions: []int{10, 16},
+			Boundaries: []Boundary{
+				{Position: 10, Confidence: 1},
+				{Position: 16, Confidence: 1},
+			},
+			Lines: 3,
+		},
+	},
+	{
+		Name:        "Header Names with Conflicts",
+		Input:       "id name  note\n1  abcdefghi\n2  ab    xyz\n",
+		HeaderNames: []string{"id", "name", "note"},
+		Expect: &DelimitResult{
+			Positions: []int{3, 9, 13},
+			Boundaries: []Boundary{
+				{Position: 3, Confidence: 1},
+				{Position: 9, Confidence: float64(2) / float64(3), Conflicts: 1},
+				{Position: 13, Confidence: 1},
+			},
+			Lines:            3,
+			ConflictingLines: 1,
+		},
+	},
+	{
+		Name:        "Header Not Found",
+		Input:       "name      amount\nabc          100\n",
+		HeaderNames: []string{"amount", "name"},
+		Error:       "header \"name\" is not found",
+	},
+	{
+		Name:        "Header Names without Header",
+		Input:       "abc          100\n",
+		NoHeader:    true,
+		HeaderNames: []string{"name"},
+		Error:       "header names require a header line",
+	},
+}
+
+func TestDelimiter_Analyze(t *testing.T) {
+	for _, v := range delimiterAnalyzeTests {
+		d, _ := NewDelimiter(strings.NewReader(v.Input), text.UTF8)
+		d.NoHeader = v.NoHeader
+		d.SampleLines = v.SampleLines
+		d.MinColumnGap = v.MinColumnGap
+		d.HeaderNames = v.HeaderNames
+
+		result, err := d.Analyze()
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(result, v.Expect) {
+			t.Errorf("%s: result = %+v, want %+v", v.Name, result, v.Expect)
+		}
+	}
+}
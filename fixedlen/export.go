@@ -0,0 +1,264 @@
+package fixedlen
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/mithrandie/go-text"
+)
+
+// ExportWriter writes records in two passes without keeping them in memory.
+// Records are spooled to a temporary file while the sizes of the fields are measured,
+// and written by Writer with the measured positions when Close is called.
+//
+// Fields that are not aligned are right-aligned if all the values in the column are numbers,
+// and left-aligned otherwise.
+type ExportWriter struct {
+	InsertSpace bool
+	PadChar     byte
+	SingleLine  bool
+
+	// NullToken is written for NULL fields.
+	NullToken string
+
+	// Unit is the unit of the measured positions.
+	Unit Unit
+
+	// TempDir is the directory of the spool file. The default directory for temporary files is used if empty.
+	// It must be set before the first call to Write.
+	TempDir string
+
+	writer  *Writer
+	header  []string
+	measure *Measure
+	numeric []bool
+
+	spool       *os.File
+	spoolWriter *bufio.Writer
+	closed      bool
+}
+
+// NewExportWriter returns a writer of the header and the records written by Write.
+// The header is not written if it is empty.
+func NewExportWriter(w io.Writer, header []string, lineBreak text.LineBreak, enc text.Encoding) (*ExportWriter, error) {
+	writer, err := NewWriter(w, nil, lineBreak, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportWriter{
+		PadChar: ' ',
+		writer:  writer,
+		header:  header,
+		measure: &Measure{Encoding: enc},
+	}, nil
+}
+
+// Write measures the record and spools it.
+func (e *ExportWriter) Write(record []Field) error {
+	if e.closed {
+		return errors.New("writer is closed")
+	}
+	if e.spool == nil {
+		if err := e.openSpool(); err != nil {
+			return err
+		}
+	}
+
+	e.measure.Unit = e.Unit
+	e.measure.Measure(e.nullReplaced(record))
+
+	for i, f := range record {
+		if len(e.numeric) <= i {
+			e.numeric = append(e.numeric, true)
+		}
+		if !f.Null && 0 < len(f.Contents) && !isNumber(f.Contents) {
+			e.numeric[i] = false
+		}
+	}
+
+	return e.spoolRecord(record)
+}
+
+func (e *ExportWriter) openSpool() error {
+	spool, err := ioutil.TempFile(e.TempDir, "fixedlen-export-")
+	if err != nil {
+		return err
+	}
+	e.spool = spool
+	e.spoolWriter = bufio.NewWriter(spool)
+	return nil
+}
+
+func (e *ExportWriter) nullReplaced(record []Field) []Field {
+	fields := make([]Field, len(record))
+	for i, f := range record {
+		if f.Null {
+			f.Contents = e.NullToken
+		}
+		fields[i] = f
+	}
+	return fields
+}
+
+// spoolRecord writes the number of fields followed by the null flag, the alignment, the length
+// and the contents of each field.
+func (e *ExportWriter) spoolRecord(record []Field) error {
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	n := binary.PutUvarint(buf, uint64(len(record)))
+	if _, err := e.spoolWriter.Write(buf[:n]); err != nil {
+		return err
+	}
+
+	for _, f := range record {
+		null := byte(0)
+		if f.Null {
+			null = 1
+		}
+		if err := e.spoolWriter.WriteByte(null); err != nil {
+			return err
+		}
+		if err := e.spoolWriter.WriteByte(byte(f.Alignment)); err != nil {
+			return err
+		}
+		n = binary.PutUvarint(buf, uint64(len(f.Contents)))
+		if _, err := e.spoolWriter.Write(buf[:n]); err != nil {
+			return err
+		}
+		if _, err := e.spoolWriter.WriteString(f.Contents); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readSpooledRecord(r *bufio.Reader) ([]Field, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	record := make([]Field, n)
+	for i := range record {
+		var flags [2]byte
+		if _, err = io.ReadFull(r, flags[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		l, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		contents := make([]byte, l)
+		if _, err = io.ReadFull(r, contents); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+
+		record[i] = Field{
+			Contents:  string(contents),
+			Alignment: text.FieldAlignment(flags[1]),
+			Null:      flags[0] == 1,
+		}
+	}
+	return record, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Close writes the header and the spooled records, and removes the spool file.
+func (e *ExportWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if e.spool != nil {
+		defer func() {
+			_ = e.spool.Close()
+			_ = os.Remove(e.spool.Name())
+		}()
+	}
+
+	header := make([]Field, len(e.header))
+	for i, name := range e.header {
+		header[i] = NewField(name, text.NotAligned)
+	}
+	e.measure.Unit = e.Unit
+	e.measure.Measure(header)
+
+	alignments := make([]text.FieldAlignment, len(e.measure.size))
+	for i := range alignments {
+		alignments[i] = text.LeftAligned
+		if i < len(e.numeric) && e.numeric[i] {
+			alignments[i] = text.RightAligned
+		}
+	}
+
+	// Sizes are measured in the unit, and empty columns are filled with a pad character.
+	minSize := e.Unit.RuneSize(rune(e.PadChar), e.measure.Encoding)
+	positions := make(DelimiterPositions, len(e.measure.size))
+	pos := 0
+	for i, size := range e.measure.size {
+		if size < minSize {
+			size = minSize
+		}
+		pos = pos + size
+		positions[i] = pos
+	}
+
+	e.writer.delimiterPositions = positions
+	e.writer.InsertSpace = e.InsertSpace
+	e.writer.PadChar = e.PadChar
+	e.writer.SingleLine = e.SingleLine
+	e.writer.NullToken = e.NullToken
+	e.writer.Unit = e.Unit
+
+	if 0 < len(header) {
+		if err := e.writeAligned(header, alignments); err != nil {
+			return err
+		}
+	}
+
+	if e.spool != nil {
+		if err := e.spoolWriter.Flush(); err != nil {
+			return err
+		}
+		if _, err := e.spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		r := bufio.NewReader(e.spool)
+		for {
+			record, err := readSpooledRecord(r)
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return err
+			}
+			if err = e.writeAligned(record, alignments); err != nil {
+				return err
+			}
+		}
+	}
+
+	return e.writer.Flush()
+}
+
+func (e *ExportWriter) writeAligned(record []Field, alignments []text.FieldAlignment) error {
+	for i := range record {
+		if record[i].Alignment == text.NotAligned {
+			record[i].Alignment = alignments[i]
+		}
+	}
+	return e.writer.Write(record)
+}
@@ -0,0 +1,149 @@
+package fixedlen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+var exportWriterTests = []struct {
+	Name        string
+	Header      []string
+	Records     [][]Field
+	InsertSpace bool
+	NullToken   string
+	Unit        Unit
+	Encoding    text.Encoding
+	Expect      string
+	Error       string
+}{
+	{
+		Name:   "Infer Alignment",
+		Header: []string{"id", "name", "amount"},
+		Records: [][]Field{
+			{NewField("1", text.NotAligned), NewField("apple", text.NotAligned), NewField("120", text.NotAligned)},
+			{NewField("12", text.NotAligned), NewField("banana", text.NotAligned), NewField("-3.5", text.NotAligned)},
+			{NewField("x", text.NotAligned), NewField("cherry", text.NotAligned), {Null: true}},
+		},
+		InsertSpace: true,
+		Expect: "" +
+			"id name   amount\n" +
+			"1  apple     120\n" +
+			"12 banana   -3.5\n" +
+			"x  cherry       ",
+	},
+	{
+		Name: "Explicit Alignment and Null Token",
+		Records: [][]Field{
+			{NewField("ab", text.Centering), NewField("10", text.NotAligned)},
+			{NewField("a", text.NotAligned), {Null: true}},
+		},
+		NullToken: "NULL",
+		Expect: "" +
+			"ab  10\n" +
+			"a NULL",
+	},
+	{
+		Name:   "Uneven Records and Empty Columns",
+		Header: []string{"a"},
+		Records: [][]Field{
+			{NewField("1", text.NotAligned)},
+			{NewField("2", text.NotAligned), NewField("", text.NotAligned), NewField("x", text.NotAligned)},
+		},
+		Expect: "" +
+			"a  \n" +
+			"1  \n" +
+			"2 x",
+	},
+	{
+		Name:   "Rune Unit",
+		Header: []string{"name"},
+		Records: [][]Field{
+			{NewField("日本語です", text.NotAligned)},
+		},
+		Unit:     Unit{PositionUnit: RuneUnit},
+		Encoding: text.SJIS,
+		Expect: "" +
+			"name \n" +
+			"日本語です",
+	},
+	{
+		Name:   "CP930",
+		Header: []string{"A"},
+		Records: [][]Field{
+			{NewField("一", text.NotAligned)},
+		},
+		Encoding: text.CP930,
+		Expect: "" +
+			"A   \n" +
+			"一",
+	},
+	{
+		Name:   "UTF16",
+		Header: []string{"id", "name"},
+		Records: [][]Field{
+			{NewField("1", text.NotAligned), NewField("abc", text.NotAligned)},
+			{NewField("123", text.NotAligned), NewField("x", text.NotAligned), NewField("", text.NotAligned)},
+		},
+		Encoding: text.UTF16LE,
+		Expect: "" +
+			" idname \n" +
+			"  1abc  \n" +
+			"123x    ",
+	},
+	{
+		Name:     "Invalid Encoding",
+		Encoding: text.AUTO,
+		Error:    text.ErrInvalidEncoding.Error(),
+	},
+}
+
+func TestExportWriter(t *testing.T) {
+	for _, v := range exportWriterTests {
+		enc := v.Encoding
+		if enc == text.AUTO && len(v.Error) < 1 {
+			enc = text.UTF8
+		}
+
+		buf := new(bytes.Buffer)
+		w, err := NewExportWriter(buf, v.Header, text.LF, enc)
+		if err == nil {
+			w.InsertSpace = v.InsertSpace
+			w.NullToken = v.NullToken
+			w.Unit = v.Unit
+			w.TempDir = t.TempDir()
+
+			for _, record := range v.Records {
+				if err = w.Write(record); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = w.Close()
+			}
+		}
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+
+		expect, _ := text.Encode([]byte(v.Expect), enc)
+		if !bytes.Equal(buf.Bytes(), expect) {
+			t.Errorf("%s: result = %q, want %q", v.Name, buf.String(), expect)
+		}
+
+		if files, _ := ioutil.ReadDir(w.TempDir); 0 < len(files) {
+			t.Errorf("%s: spool file is not removed", v.Name)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package fixedlen
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/mithrandie/go-text/internal/structs"
+)
+
+// StructPositions returns the delimiter positions generated from the "pos" options in the "text" tag
+// of the struct type of the elements of the slice v.
+// Gaps between fields are treated as unnamed fields.
+func StructPositions(v interface{}) (DelimiterPositions, error) {
+	t, err := structs.StructOf(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := positionedFields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Start < fields[j].Start
+	})
+
+	positions := make(DelimiterPositions, 0, len(fields)*2)
+	for i, f := range fields {
+		if f.Start < positions.Last() {
+			return nil, errors.New(fmt.Sprintf("field %s overlaps field %s", f.Name, fields[i-1].Name))
+		}
+		if positions.Last() < f.Start {
+			positions = append(positions, f.Start)
+		}
+		positions = append(positions, f.End)
+	}
+	return positions, nil
+}
+
+// Unmarshal reads all records from r, and stores them into the slice pointed to by v.
+// Struct fields are mapped to the fields of the delimiter positions by the "pos" options in the "text" tag.
+func Unmarshal(r *Reader, v interface{}) error {
+	slice, t, err := structs.SliceOf(v)
+	if err != nil {
+		return err
+	}
+	fields, err := positionedFields(t)
+	if err != nil {
+		return err
+	}
+	columns, err := fieldColumns(fields, r.DelimiterPositions)
+	if err != nil {
+		return err
+	}
+
+	for n := 1; ; n++ {
+		record, err := r.parseRecord(r.WithoutNull, false)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		elem := structs.AppendElem(slice)
+		for i, f := range fields {
+			if err = structs.Decode(record[columns[i]], elem.FieldByIndex(f.Index), f.Format); err != nil {
+				return errors.New(fmt.Sprintf("record %d, field %s: %s", n, f.Name, err.Error()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Marshal writes the elements of the slice v to w.
+// Fields are aligned by the "align" options in the "text" tag.
+func Marshal(w *Writer, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	t, err := structs.StructOf(v)
+	if err != nil {
+		return err
+	}
+	fields, err := positionedFields(t)
+	if err != nil {
+		return err
+	}
+	columns, err := fieldColumns(fields, w.delimiterPositions)
+	if err != nil {
+		return err
+	}
+
+	record := make([]Field, len(w.delimiterPositions))
+	for i := 0; i < rv.Len(); i++ {
+		for j := range record {
+			record[j] = Field{}
+		}
+
+		if elem, ok := structs.Elem(rv, i); ok {
+			for j, f := range fields {
+				s, isNull, err := structs.Encode(elem.FieldByIndex(f.Index), f.Format)
+				if err != nil {
+					return errors.New(fmt.Sprintf("field %s: %s", f.Name, err.Error()))
+				}
+				record[columns[j]] = NewField(s, f.Alignment)
+				record[columns[j]].Null = isNull
+			}
+		}
+
+		if err = w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func positionedFields(t reflect.Type) ([]structs.Field, error) {
+	fields, err := structs.Fields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	positioned := make([]structs.Field, 0, len(fields))
+	for _, f := range fields {
+		if f.HasPosition() {
+			positioned = append(positioned, f)
+		}
+	}
+	return positioned, nil
+}
+
+func fieldColumns(fields []structs.Field, positions DelimiterPositions) ([]int, error) {
+	columns := make([]int, len(fields))
+
+	for i, f := range fields {
+		columns[i] = -1
+
+		start := 0
+		for j, end := range positions {
+			if start == f.Start && end == f.End {
+				columns[i] = j
+				break
+			}
+			start = end
+		}
+
+		if columns[i] < 0 {
+			return nil, errors.New(fmt.Sprintf("field %s: position %d:%d does not match delimiter positions %s", f.Name, f.Start, f.End, positions))
+		}
+	}
+	return columns, nil
+}
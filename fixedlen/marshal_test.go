@@ -0,0 +1,91 @@
+package fixedlen
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+type marshalTestRecord struct {
+	Code   string `text:"code,pos=0:4"`
+	Amount int    `text:"amount,pos=6:12,align=right"`
+	Name   string `text:"name,pos=12:20"`
+	Memo   string
+}
+
+func TestStructPositions(t *testing.T) {
+	expect := DelimiterPositions{4, 6, 12, 20}
+
+	positions, err := StructPositions([]marshalTestRecord{})
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(positions, expect) {
+		t.Errorf("positions = %s, want %s", positions, expect)
+	}
+
+	expectErr := "field b overlaps field a"
+	_, err = StructPositions([]struct {
+		A string `text:"a,pos=0:4"`
+		B string `text:"b,pos=3:6"`
+	}{})
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := "A001  001200abc     \nA002      -5日本語  \n"
+	expect := []marshalTestRecord{
+		{Code: "A001", Amount: 1200, Name: "abc"},
+		{Code: "A002", Amount: -5, Name: "日本語"},
+	}
+
+	positions, _ := StructPositions([]marshalTestRecord{})
+	sjis, _ := text.Encode([]byte(input), text.SJIS)
+	r, _ := NewReader(bytes.NewReader(sjis), positions, text.SJIS)
+	var records []marshalTestRecord
+	if err := Unmarshal(r, &records); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(records, expect) {
+		t.Errorf("records = %v, want %v", records, expect)
+	}
+
+	expectErr := "field amount: position 6:12 does not match delimiter positions [4, 12, 20]"
+	r, _ = NewReader(bytes.NewReader(sjis), []int{4, 12, 20}, text.SJIS)
+	err := Unmarshal(r, &records)
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	records := []marshalTestRecord{
+		{Code: "A001", Amount: 1200, Name: "abc"},
+		{Code: "A002", Amount: -5, Name: "日本語"},
+	}
+	expect := "A001    1200abc     \n" +
+		"A002      -5日本語  "
+
+	positions, _ := StructPositions(records)
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, positions, text.LF, text.SJIS)
+	if err := Marshal(w, records); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	result, _ := text.Decode(buf.Bytes(), text.SJIS)
+	if string(result) != expect {
+		t.Errorf("result = %q, want %q", string(result), expect)
+	}
+}
@@ -4,7 +4,11 @@ import "github.com/mithrandie/go-text"
 
 type Measure struct {
 	Encoding text.Encoding
-	size     []int
+
+	// Unit is the unit of the positions generated by GeneratePositions.
+	Unit Unit
+
+	size []int
 }
 
 func NewMeasure() *Measure {
@@ -17,7 +21,7 @@ func (m *Measure) Measure(record []Field) {
 	}
 
 	for i, v := range record {
-		l := text.ByteSize(v.Contents, m.Encoding)
+		l := m.Unit.Size(v.Contents, m.Encoding)
 		if len(m.size) <= i {
 			m.size = append(m.size, l)
 		} else if m.size[i] < l {
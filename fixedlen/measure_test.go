@@ -11,6 +11,7 @@ var meagureMeasureTests = []struct {
 	Name     string
 	Records  [][]Field
 	Encoding text.Encoding
+	Unit     Unit
 	Expect   DelimiterPositions
 }{
 	{
@@ -82,12 +83,37 @@ var meagureMeasureTests = []struct {
 		Encoding: text.SJIS,
 		Expect:   DelimiterPositions{3, 9, 12},
 	},
+	{
+		Name: "Multibyte Characters in Runes",
+		Records: [][]Field{
+			{
+				{Contents: "abc", Alignment: text.LeftAligned},
+				{Contents: "日本語", Alignment: text.LeftAligned},
+			},
+		},
+		Encoding: text.UTF8,
+		Unit:     Unit{PositionUnit: RuneUnit},
+		Expect:   DelimiterPositions{3, 6},
+	},
+	{
+		Name: "Multibyte Characters in Display Width",
+		Records: [][]Field{
+			{
+				{Contents: "abc", Alignment: text.LeftAligned},
+				{Contents: "日本語", Alignment: text.LeftAligned},
+			},
+		},
+		Encoding: text.UTF8,
+		Unit:     Unit{PositionUnit: WidthUnit},
+		Expect:   DelimiterPositions{3, 9},
+	},
 }
 
 func TestMeasure_Measure(t *testing.T) {
 	for _, v := range meagureMeasureTests {
 		m := NewMeasure()
 		m.Encoding = v.Encoding
+		m.Unit = v.Unit
 
 		for _, record := range v.Records {
 			m.Measure(record)
@@ -0,0 +1,409 @@
+package fixedlen
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mithrandie/go-text"
+)
+
+// Discriminator is the position of the record-type code in records.
+// Start is zero-based, and End is exclusive.
+type Discriminator struct {
+	Start int
+	End   int
+}
+
+// Layout is a record layout selected by record-type codes.
+type Layout struct {
+	Name   string
+	Codes  []string
+	Schema *Schema
+}
+
+// TrailerCount defines a field of trailer records holding the number of records
+// since the previous trailer record.
+type TrailerCount struct {
+	// Layout is the name of the trailer layout, and Field is the name of the field.
+	Layout string
+	Field  string
+	// Counted are the names of the layouts counted. All records except trailers are counted if empty.
+	Counted []string
+}
+
+type layoutSet struct {
+	layouts      []Layout
+	byName       map[string]int
+	trailerCount *TrailerCount
+	countField   int
+	counted      map[string]bool
+	count        int
+}
+
+func newLayoutSet(layouts []Layout, trailerCount *TrailerCount) (*layoutSet, error) {
+	if len(layouts) < 1 {
+		return nil, errors.New("no layouts")
+	}
+
+	set := &layoutSet{
+		layouts:      layouts,
+		byName:       make(map[string]int, len(layouts)),
+		trailerCount: trailerCount,
+		countField:   -1,
+	}
+
+	codes := make(map[string]string)
+	for i, l := range layouts {
+		if len(l.Name) < 1 {
+			return nil, errors.New(fmt.Sprintf("layout %d: name is empty", i+1))
+		}
+		if _, ok := set.byName[l.Name]; ok {
+			return nil, errors.New(fmt.Sprintf("duplicate layout: %q", l.Name))
+		}
+		if l.Schema == nil {
+			return nil, errors.New(fmt.Sprintf("layout %s: schema is not specified", l.Name))
+		}
+		if err := l.Schema.Validate(); err != nil {
+			return nil, errors.New(fmt.Sprintf("layout %s: %s", l.Name, err.Error()))
+		}
+		if l.Schema.Unit != layouts[0].Schema.Unit {
+			return nil, errors.New(fmt.Sprintf("layout %s: unit differs from layout %s", l.Name, layouts[0].Name))
+		}
+		for _, c := range l.Codes {
+			if other, ok := codes[c]; ok {
+				return nil, errors.New(fmt.Sprintf("layout %s: code %q is used by layout %s", l.Name, c, other))
+			}
+			codes[c] = l.Name
+		}
+		set.byName[l.Name] = i
+	}
+
+	if trailerCount != nil {
+		trailer, ok := set.byName[trailerCount.Layout]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("trailer layout %s is not found", trailerCount.Layout))
+		}
+		for i, f := range layouts[trailer].Schema.Fields {
+			if f.Name == trailerCount.Field {
+				set.countField = i
+				break
+			}
+		}
+		if set.countField < 0 {
+			return nil, errors.New(fmt.Sprintf("field %s is not found in trailer layout %s", trailerCount.Field, trailerCount.Layout))
+		}
+		if 0 < len(trailerCount.Counted) {
+			set.counted = make(map[string]bool, len(trailerCount.Counted))
+			for _, name := range trailerCount.Counted {
+				if _, ok := set.byName[name]; !ok {
+					return nil, errors.New(fmt.Sprintf("counted layout %s is not found", name))
+				}
+				set.counted[name] = true
+			}
+		}
+	}
+	return set, nil
+}
+
+func (set *layoutSet) lookupCode(code string) (Layout, bool) {
+	for _, l := range set.layouts {
+		for _, c := range l.Codes {
+			if c == code {
+				return l, true
+			}
+		}
+	}
+	return Layout{}, false
+}
+
+func (set *layoutSet) isTrailer(name string) bool {
+	return set.trailerCount != nil && name == set.trailerCount.Layout
+}
+
+// checkTrailer returns the expected and the actual count for trailer records.
+// The record is not counted until countRecord is called.
+func (set *layoutSet) checkTrailer(name string, countValue string) (int, int, bool, error) {
+	if !set.isTrailer(name) {
+		return 0, 0, true, nil
+	}
+
+	expect, err := strconv.Atoi(string(bytes.TrimSpace([]byte(countValue))))
+	if err != nil {
+		return 0, set.count, false, errors.New(fmt.Sprintf("invalid record count: %q", countValue))
+	}
+	return expect, set.count, expect == set.count, nil
+}
+
+// countRecord counts the record, or resets the count for trailer records.
+func (set *layoutSet) countRecord(name string) {
+	if set.trailerCount == nil {
+		return
+	}
+
+	if set.isTrailer(name) {
+		set.count = 0
+	} else if set.counted == nil || set.counted[name] {
+		set.count++
+	}
+}
+
+// MultiReader reads fixed-length records of multiple layouts.
+// The layout of each record is selected by the record-type code at the position of Discriminator.
+type MultiReader struct {
+	Discriminator Discriminator
+	WithoutNull   bool
+	SingleLine    bool
+
+	// NullTokens are the trimmed values read as NULL in addition to blank fields.
+	NullTokens text.NullTokens
+
+	reader *Reader
+	set    *layoutSet
+}
+
+// NewMultiReader returns a reader of the layouts. All layouts must have the same unit.
+// If trailerCount is not nil, Read returns an error when the count in a trailer record does not match
+// the number of records, or when records are not followed by a trailer record.
+func NewMultiReader(r io.Reader, layouts []Layout, discriminator Discriminator, trailerCount *TrailerCount, enc text.Encoding) (*MultiReader, error) {
+	if discriminator.Start < 0 || discriminator.End <= discriminator.Start {
+		return nil, errors.New(fmt.Sprintf("invalid discriminator position %d:%d", discriminator.Start, discriminator.End))
+	}
+
+	set, err := newLayoutSet(layouts, trailerCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range layouts {
+		if l.Schema.HasDecimalFormats() {
+			return nil, errors.New(fmt.Sprintf("layout %s: reading fields other than text format is not supported", l.Name))
+		}
+	}
+
+	reader, err := NewReader(r, nil, enc)
+	if err != nil {
+		return nil, err
+	}
+	reader.Unit = layouts[0].Schema.Unit
+
+	return &MultiReader{
+		Discriminator: discriminator,
+		reader:        reader,
+		set:           set,
+	}, nil
+}
+
+// Read returns the name of the layout and the fields of the next record.
+func (r *MultiReader) Read() (string, []text.RawText, error) {
+	code, err := r.reader.peekCode(r.Discriminator)
+	if err != nil {
+		if err == io.EOF && 0 < r.set.count {
+			return "", nil, &text.ParseError{
+				Kind:    text.RecordCountError,
+				Line:    r.reader.line,
+				Offset:  r.reader.offset,
+				Record:  r.reader.record,
+				Message: fmt.Sprintf("%d records are not followed by a trailer record", r.set.count),
+			}
+		}
+		return "", nil, err
+	}
+
+	layout, ok := r.set.lookupCode(code)
+	if !ok {
+		return "", nil, &text.ParseError{
+			Kind:    text.RecordTypeError,
+			Line:    r.reader.line,
+			Column:  r.Discriminator.Start + 1,
+			Offset:  r.reader.offset,
+			Record:  r.reader.record,
+			Message: fmt.Sprintf("unknown record type: %q", code),
+		}
+	}
+
+	r.reader.DelimiterPositions = layout.Schema.Positions()
+	r.reader.schema = layout.Schema
+	r.reader.WithoutNull = r.WithoutNull
+	r.reader.SingleLine = r.SingleLine
+	r.reader.NullTokens = r.NullTokens
+
+	line := r.reader.line
+	offset := r.reader.offset
+	record, err := r.reader.Read()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var countValue string
+	if r.set.isTrailer(layout.Name) {
+		countValue = string(record[r.set.countField])
+	}
+	expect, count, ok, err := r.set.checkTrailer(layout.Name, countValue)
+	r.set.countRecord(layout.Name)
+	if err != nil || !ok {
+		field := layout.Schema.Fields[r.set.countField]
+		msg := fmt.Sprintf("trailer record count %d does not match %d records", expect, count)
+		kind := text.RecordCountError
+		if err != nil {
+			msg = err.Error()
+			kind = text.DataFormatError
+		}
+		return "", nil, &text.ParseError{
+			Kind:    kind,
+			Line:    line,
+			Column:  field.Start + 1,
+			Offset:  offset,
+			Record:  r.reader.record - 1,
+			Field:   r.set.countField,
+			Message: msg,
+		}
+	}
+
+	return layout.Name, record, nil
+}
+
+// peekCode returns the text at the position of the discriminator in the next record without reading it.
+func (r *Reader) peekCode(d Discriminator) (string, error) {
+	for n := 64; ; n = n * 2 {
+		b, err := r.reader.Peek(n)
+		if len(b) < 1 && err != nil {
+			return "", err
+		}
+
+		code := make([]rune, 0, d.End-d.Start)
+		pos := 0
+		shifted := false
+		complete := false
+		for i := 0; i < len(b); {
+			if !utf8.FullRune(b[i:]) {
+				break
+			}
+			c, size := utf8.DecodeRune(b[i:])
+			if c == '\n' || c == '\r' {
+				complete = true
+				break
+			}
+
+			s := r.Unit.RuneSize(c, r.Encoding)
+			if r.Unit.PositionUnit == ByteUnit && text.IsShiftEncoding(r.Encoding) {
+				if s < 2 {
+					shifted = false
+				} else if !shifted {
+					s = s + 2
+					shifted = true
+				}
+			}
+			if d.Start <= pos && pos+s <= d.End {
+				code = append(code, c)
+			}
+			pos = pos + s
+			i = i + size
+
+			if d.End <= pos {
+				complete = true
+				break
+			}
+		}
+
+		if complete || err != nil {
+			if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+				return "", err
+			}
+			return string(code), nil
+		}
+	}
+}
+
+// MultiWriter writes fixed-length records of multiple layouts.
+type MultiWriter struct {
+	InsertSpace bool
+	PadChar     byte
+	SingleLine  bool
+
+	// NullToken is written for NULL fields with the alignment of the field.
+	NullToken string
+
+	// OverflowPolicy, OverflowMarker, Overflows and ZeroPadNumbers work the same as those of Writer.
+	OverflowPolicy OverflowPolicy
+	OverflowMarker string
+	Overflows      []OverflowField
+	ZeroPadNumbers bool
+
+	writer *Writer
+	set    *layoutSet
+}
+
+// NewMultiWriter returns a writer of the layouts. All layouts must have the same unit.
+// If trailerCount is not nil, Write returns an error when the count in a trailer record does not match
+// the number of records written.
+func NewMultiWriter(w io.Writer, layouts []Layout, trailerCount *TrailerCount, lineBreak text.LineBreak, enc text.Encoding) (*MultiWriter, error) {
+	set, err := newLayoutSet(layouts, trailerCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range layouts {
+		if l.Schema.HasDecimalFormats() {
+			return nil, errors.New(fmt.Sprintf("layout %s: writing fields other than text format is not supported", l.Name))
+		}
+	}
+
+	writer, err := NewWriter(w, nil, lineBreak, enc)
+	if err != nil {
+		return nil, err
+	}
+	writer.Unit = layouts[0].Schema.Unit
+
+	return &MultiWriter{
+		PadChar:        ' ',
+		OverflowMarker: writer.OverflowMarker,
+		writer:         writer,
+		set:            set,
+	}, nil
+}
+
+// Write writes the record in the layout.
+func (e *MultiWriter) Write(layout string, record []Field) error {
+	i, ok := e.set.byName[layout]
+	if !ok {
+		return errors.New(fmt.Sprintf("layout %s is not found", layout))
+	}
+	l := e.set.layouts[i]
+
+	var countValue string
+	if e.set.isTrailer(l.Name) && e.set.countField < len(record) {
+		countValue = record[e.set.countField].Contents
+	}
+	expect, count, ok, err := e.set.checkTrailer(l.Name, countValue)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(fmt.Sprintf("trailer record count %d does not match %d records", expect, count))
+	}
+
+	e.writer.delimiterPositions = l.Schema.Positions()
+	e.writer.schema = l.Schema
+	e.writer.InsertSpace = e.InsertSpace
+	e.writer.PadChar = e.PadChar
+	e.writer.SingleLine = e.SingleLine
+	e.writer.NullToken = e.NullToken
+	e.writer.OverflowPolicy = e.OverflowPolicy
+	e.writer.OverflowMarker = e.OverflowMarker
+	e.writer.ZeroPadNumbers = e.ZeroPadNumbers
+	err = e.writer.Write(record)
+	e.Overflows = e.writer.Overflows
+	if err != nil {
+		return err
+	}
+
+	e.set.countRecord(l.Name)
+	return nil
+}
+
+func (e *MultiWriter) Flush() error {
+	return e.writer.Flush()
+}
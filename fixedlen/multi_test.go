@@ -0,0 +1,329 @@
+package fixedlen
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+func testLayouts() []Layout {
+	return []Layout{
+		{
+			Name:  "header",
+			Codes: []string{"H"},
+			Schema: &Schema{Fields: []SchemaField{
+				{Name: "type", Length: 1},
+				{Name: "date", Start: 1, Length: 8},
+			}},
+		},
+		{
+			Name:  "detail",
+			Codes: []string{"D", "E"},
+			Schema: &Schema{Fields: []SchemaField{
+				{Name: "type", Length: 1},
+				{Name: "id", Start: 1, Length: 4},
+				{Name: "amount", Start: 5, Length: 6, Alignment: text.RightAligned},
+			}},
+		},
+		{
+			Name:  "trailer",
+			Codes: []string{"T"},
+			Schema: &Schema{Fields: []SchemaField{
+				{Name: "type", Length: 1},
+				{Name: "count", Start: 1, Length: 5, Alignment: text.RightAligned, PadChar: '0'},
+			}},
+		},
+	}
+}
+
+type multiRecord struct {
+	Layout string
+	Fields []text.RawText
+}
+
+var multiReaderReadTests = []struct {
+	Name         string
+	Input        string
+	SingleLine   bool
+	TrailerCount *TrailerCount
+	Encoding     text.Encoding
+	Output       []multiRecord
+	Error        string
+}{
+	{
+		Name: "Read",
+		Input: "" +
+			"H20240101\n" +
+			"D0001   100\n" +
+			"E0002  2500\n" +
+			"T00002\n",
+		TrailerCount: &TrailerCount{Layout: "trailer", Field: "count", Counted: []string{"detail"}},
+		Output: []multiRecord{
+			{Layout: "header", Fields: []text.RawText{text.RawText("H"), text.RawText("20240101")}},
+			{Layout: "detail", Fields: []text.RawText{text.RawText("D"), text.RawText("0001"), text.RawText("100")}},
+			{Layout: "detail", Fields: []text.RawText{text.RawText("E"), text.RawText("0002"), text.RawText("2500")}},
+			{Layout: "trailer", Fields: []text.RawText{text.RawText("T"), text.RawText("2")}},
+		},
+	},
+	{
+		Name:       "Read SingleLine",
+		Input:      "H20240101D0001   100T00002",
+		SingleLine: true,
+		Output: []multiRecord{
+			{Layout: "header", Fields: []text.RawText{text.RawText("H"), text.RawText("20240101")}},
+			{Layout: "detail", Fields: []text.RawText{text.RawText("D"), text.RawText("0001"), text.RawText("100")}},
+			{Layout: "trailer", Fields: []text.RawText{text.RawText("T"), text.RawText("2")}},
+		},
+	},
+	{
+		Name:     "Read CP930",
+		Input:    "\xc8\xf2\xf0\xf2\xf4\xf0\xf1\xf0\xf1\x25\xc4\xf0\xf0\xf0\xf1\x40\x40\x40\xf1\xf0\xf0",
+		Encoding: text.CP930,
+		Output: []multiRecord{
+			{Layout: "header", Fields: []text.RawText{text.RawText("H"), text.RawText("20240101")}},
+			{Layout: "detail", Fields: []text.RawText{text.RawText("D"), text.RawText("0001"), text.RawText("100")}},
+		},
+	},
+	{
+		Name: "Count All Records",
+		Input: "" +
+			"H20240101\n" +
+			"D0001   100\n" +
+			"T00002\n",
+		TrailerCount: &TrailerCount{Layout: "trailer", Field: "count"},
+		Output: []multiRecord{
+			{Layout: "header", Fields: []text.RawText{text.RawText("H"), text.RawText("20240101")}},
+			{Layout: "detail", Fields: []text.RawText{text.RawText("D"), text.RawText("0001"), text.RawText("100")}},
+			{Layout: "trailer", Fields: []text.RawText{text.RawText("T"), text.RawText("2")}},
+		},
+	},
+	{
+		Name: "Unknown Record Type",
+		Input: "" +
+			"H20240101\n" +
+			"X0001   100\n",
+		Error: "line 2, column 1: unknown record type: \"X\"",
+	},
+	{
+		Name: "Trailer Count Mismatch",
+		Input: "" +
+			"H20240101\n" +
+			"D0001   100\n" +
+			"T00003\n",
+		TrailerCount: &TrailerCount{Layout: "trailer", Field: "count", Counted: []string{"detail"}},
+		Error:        "line 3, column 2: trailer record count 3 does not match 1 records",
+	},
+	{
+		Name: "Invalid Trailer Count",
+		Input: "" +
+			"D0001   100\n" +
+			"T000x1\n",
+		TrailerCount: &TrailerCount{Layout: "trailer", Field: "count"},
+		Error:        "line 2, column 2: invalid record count: \"x1\"",
+	},
+	{
+		Name: "Missing Trailer",
+		Input: "" +
+			"D0001   100\n" +
+			"T00001\n" +
+			"D0002   200\n",
+		TrailerCount: &TrailerCount{Layout: "trailer", Field: "count"},
+		Error:        "line 4, column 0: 1 records are not followed by a trailer record",
+	},
+}
+
+func TestMultiReader_Read(t *testing.T) {
+	for _, v := range multiReaderReadTests {
+		enc := v.Encoding
+		if enc == text.AUTO {
+			enc = text.UTF8
+		}
+
+		r, err := NewMultiReader(strings.NewReader(v.Input), testLayouts(), Discriminator{Start: 0, End: 1}, v.TrailerCount, enc)
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		r.SingleLine = v.SingleLine
+
+		records := make([]multiRecord, 0, len(v.Output))
+		for {
+			layout, record, e := r.Read()
+			if e != nil {
+				if e != io.EOF {
+					err = e
+				}
+				break
+			}
+			records = append(records, multiRecord{Layout: layout, Fields: record})
+		}
+
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err, v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %q, want %q", v.Name, records, v.Output)
+		}
+	}
+}
+
+func TestMultiReader_ReadParseError(t *testing.T) {
+	input := "H20240101\nD0001   100\nT00005\n"
+	r, _ := NewMultiReader(strings.NewReader(input), testLayouts(), Discriminator{Start: 0, End: 1}, &TrailerCount{Layout: "trailer", Field: "count"}, text.UTF8)
+
+	var err error
+	for err == nil {
+		_, _, err = r.Read()
+	}
+
+	var perr *text.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *text.ParseError", err)
+	}
+	expect := &text.ParseError{
+		Kind:    text.RecordCountError,
+		Line:    3,
+		Column:  2,
+		Offset:  22,
+		Record:  2,
+		Field:   1,
+		Message: "trailer record count 5 does not match 2 records",
+	}
+	if !reflect.DeepEqual(perr, expect) {
+		t.Errorf("error = %#v, want %#v", perr, expect)
+	}
+}
+
+var newMultiReaderTests = []struct {
+	Name          string
+	Layouts       []Layout
+	Discriminator Discriminator
+	TrailerCount  *TrailerCount
+	Error         string
+}{
+	{
+		Name:          "Invalid Discriminator",
+		Layouts:       testLayouts(),
+		Discriminator: Discriminator{Start: 1, End: 1},
+		Error:         "invalid discriminator position 1:1",
+	},
+	{
+		Name: "Duplicate Code",
+		Layouts: append(testLayouts(), Layout{
+			Name:   "other",
+			Codes:  []string{"E"},
+			Schema: &Schema{Fields: []SchemaField{{Name: "type", Length: 1}}},
+		}),
+		Discriminator: Discriminator{Start: 0, End: 1},
+		Error:         "layout other: code \"E\" is used by layout detail",
+	},
+	{
+		Name:          "Trailer Field Not Found",
+		Layouts:       testLayouts(),
+		Discriminator: Discriminator{Start: 0, End: 1},
+		TrailerCount:  &TrailerCount{Layout: "trailer", Field: "total"},
+		Error:         "field total is not found in trailer layout trailer",
+	},
+	{
+		Name: "Different Units",
+		Layouts: append(testLayouts(), Layout{
+			Name:   "other",
+			Codes:  []string{"O"},
+			Schema: &Schema{Fields: []SchemaField{{Name: "type", Length: 1}}, Unit: Unit{PositionUnit: RuneUnit}},
+		}),
+		Discriminator: Discriminator{Start: 0, End: 1},
+		Error:         "layout other: unit differs from layout header",
+	},
+	{
+		Name: "Decimal Formats",
+		Layouts: append(testLayouts(), Layout{
+			Name:   "packed",
+			Codes:  []string{"P"},
+			Schema: &Schema{Fields: []SchemaField{{Name: "type", Length: 1}, {Name: "amount", Start: 1, Length: 4, Format: PackedDecimalFormat}}},
+		}),
+		Discriminator: Discriminator{Start: 0, End: 1},
+		Error:         "layout packed: reading fields other than text format is not supported",
+	},
+}
+
+func TestNewMultiReader(t *testing.T) {
+	for _, v := range newMultiReaderTests {
+		_, err := NewMultiReader(strings.NewReader(""), v.Layouts, v.Discriminator, v.TrailerCount, text.UTF8)
+		if err == nil {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+		} else if err.Error() != v.Error {
+			t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+		}
+	}
+}
+
+func TestMultiWriter_Write(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w, err := NewMultiWriter(buf, testLayouts(), &TrailerCount{Layout: "trailer", Field: "count", Counted: []string{"detail"}}, text.LF, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	records := []struct {
+		Layout string
+		Fields []Field
+	}{
+		{Layout: "header", Fields: []Field{NewField("H", text.NotAligned), NewField("20240101", text.NotAligned)}},
+		{Layout: "detail", Fields: []Field{NewField("D", text.NotAligned), NewField("0001", text.NotAligned), NewField("100", text.NotAligned)}},
+		{Layout: "trailer", Fields: []Field{NewField("T", text.NotAligned), NewField("1", text.NotAligned)}},
+	}
+	for _, r := range records {
+		if err = w.Write(r.Layout, r.Fields); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+	}
+	_ = w.Flush()
+
+	expect := "" +
+		"H20240101\n" +
+		"D0001   100\n" +
+		"T00001"
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+
+	if err = w.Write("detail", records[1].Fields); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	err = w.Write("trailer", records[2].Fields[:1])
+	if err == nil || err.Error() != "invalid record count: \"\"" {
+		t.Errorf("error = %v, want invalid record count error", err)
+	}
+
+	err = w.Write("footer", nil)
+	if err == nil || err.Error() != "layout footer is not found" {
+		t.Errorf("error = %v, want layout not found error", err)
+	}
+
+	err = w.Write("trailer", []Field{NewField("T", text.NotAligned), NewField("3", text.NotAligned)})
+	if err == nil || err.Error() != "trailer record count 3 does not match 1 records" {
+		t.Errorf("error = %v, want record count error", err)
+	}
+
+	err = w.Write("detail", []Field{NewField("D", text.NotAligned), NewField("0002", text.NotAligned), NewField("1234567", text.NotAligned)})
+	if err == nil || err.Error() != "value is too long: \"1234567\" for 6 byte(s) length field" {
+		t.Errorf("error = %v, want value too long error", err)
+	}
+	if err = w.Write("trailer", records[2].Fields); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	}
+}
@@ -3,41 +3,160 @@ package fixedlen
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"unicode/utf8"
 
 	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/internal/source"
 )
 
+type Framing int
+
+const (
+	// LineFraming separates records by line breaks, or concatenates records if SingleLine is true.
+	LineFraming Framing = iota
+	// RDWFraming precedes each record with a 4-byte Record Descriptor Word holding the big-endian length
+	// of the record including the RDW.
+	RDWFraming
+	// LengthPrefixFraming precedes each record with ASCII digits of the length of the record.
+	LengthPrefixFraming
+)
+
+var FramingLiteral = map[Framing]string{
+	LineFraming:         "LINE",
+	RDWFraming:          "RDW",
+	LengthPrefixFraming: "LENGTH_PREFIX",
+}
+
+func (f Framing) String() string {
+	return FramingLiteral[f]
+}
+
 type Reader struct {
 	DelimiterPositions DelimiterPositions
 	WithoutNull        bool
 	Encoding           text.Encoding
 	SingleLine         bool
 
+	// Unit is the unit of DelimiterPositions.
+	Unit Unit
+
+	// ReuseRecord makes Read return a record sharing the reader's buffers.
+	// The record is valid only until the next call to Read.
+	ReuseRecord bool
+
+	// NullTokens are the trimmed values read as NULL in addition to blank fields.
+	NullTokens text.NullTokens
+
+	// KeepSource keeps the original text of the last record read, which Source returns.
+	// It must be set before the first call to Read.
+	KeepSource bool
+
+	// Framing determines how records are separated. It must be set before the first call to Read.
+	// Records framed by RDWFraming and LengthPrefixFraming are decoded individually, and
+	// line numbers in errors are the numbers of the records.
+	Framing Framing
+	// BlockDescriptor makes RDWFraming read a Block Descriptor Word preceding each block of records.
+	BlockDescriptor bool
+	// LengthPrefixSize is the number of digits of the length in LengthPrefixFraming.
+	LengthPrefixSize int
+
+	// RecordSeparator is the bytes following each record read as bytes by a schema reader unless SingleLine is true.
+	// NewSchemaReader sets it to the line feed in the encoding, such as 0x25 in EBCDIC,
+	// and a carriage return preceding the line feed is also skipped.
+	// Records not followed by it are errors, except for the last record.
+	RecordSeparator []byte
+	// crlf is CRLF in the encoding, which is skipped as a record separator if RecordSeparator is the line feed.
+	crlf []byte
+
+	schema *Schema
 	reader *bufio.Reader
+	raw    *bufio.Reader
+	tap    *source.Tap
 	buf    bytes.Buffer
+	rawBuf []byte
+
+	blockRemaining int
+
+	line         int
+	record       int
+	offset       int64
+	runeSize     int
+	recordOffset int64
+
+	// shifted is true in a run of double-byte characters of CP930 and CP939,
+	// and shiftSize is the size of the shift codes counted for the last rune.
+	shifted     bool
+	prevShifted bool
+	shiftSize   int
+
+	recordLine int
+
+	recordBuf     []byte
+	fieldStartPos []int
+	lastRecord    []text.RawText
 
 	DetectedLineBreak text.LineBreak
 }
 
 func NewReader(r io.Reader, positions []int, enc text.Encoding) (*Reader, error) {
-	decoder, err := text.GetTransformDecoder(r, enc)
+	tap := source.NewTap(r)
+	decoder, err := text.GetTransformDecoder(tap, enc)
 	if err != nil {
 		return nil, err
 	}
+	_, bom, _ := text.SeekEncoding(enc)
 
 	return &Reader{
 		DelimiterPositions: positions,
 		WithoutNull:        false,
 		Encoding:           enc,
 		reader:             bufio.NewReader(decoder),
+		tap:                tap,
+		line:               1,
+		offset:             int64(bom),
+		LengthPrefixSize:   4,
 	}, nil
 }
 
+// NewSchemaReader returns a reader that delimits fields by the schema.
+//
+// If the schema has fields other than TextFormat, records are read as bytes of the length of the schema,
+// and text fields are decoded individually. RecordSeparator following a record is skipped unless SingleLine is true.
+func NewSchemaReader(r io.Reader, schema *Schema, enc text.Encoding) (*Reader, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+
+	reader, err := NewReader(r, schema.Positions(), enc)
+	if err != nil {
+		return nil, err
+	}
+	reader.Unit = schema.Unit
+	reader.schema = schema
+	if schema.HasDecimalFormats() {
+		reader.raw = bufio.NewReader(reader.tap)
+		reader.offset = 0
+		sepEnc := enc
+		if e, _, err := text.SeekEncoding(enc); err == nil {
+			sepEnc = e
+		}
+		if reader.RecordSeparator, err = text.Encode([]byte(text.LF.Value()), sepEnc); err != nil {
+			return nil, err
+		}
+		if reader.crlf, err = text.Encode([]byte(text.CRLF.Value()), sepEnc); err != nil {
+			return nil, err
+		}
+	}
+	return reader, nil
+}
+
 func (r *Reader) ReadHeader() ([]string, error) {
-	record, err := r.parseRecord(true)
+	record, err := r.parseRecord(true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -50,14 +169,14 @@ func (r *Reader) ReadHeader() ([]string, error) {
 }
 
 func (r *Reader) Read() ([]text.RawText, error) {
-	return r.parseRecord(r.WithoutNull)
+	return r.parseRecord(r.WithoutNull, r.ReuseRecord)
 }
 
 func (r *Reader) ReadAll() ([][]text.RawText, error) {
 	records := make([][]text.RawText, 0, 100)
 
 	for {
-		record, err := r.Read()
+		record, err := r.parseRecord(r.WithoutNull, false)
 		if err == io.EOF {
 			break
 		}
@@ -70,8 +189,77 @@ func (r *Reader) ReadAll() ([][]text.RawText, error) {
 	return records, nil
 }
 
-func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
-	record := make([]text.RawText, len(r.DelimiterPositions))
+// Source returns the original text of the last record read if KeepSource is true.
+func (r *Reader) Source() text.Source {
+	return text.NewSource(r.tap.Bytes(r.recordOffset, r.offset), r.Encoding, r.recordLine, r.recordOffset)
+}
+
+func (r *Reader) readRune() (rune, error) {
+	ch, size, err := r.reader.ReadRune()
+	if err == nil {
+		r.runeSize = text.SourceRuneSize(ch, size, r.Encoding)
+		r.shiftSize = r.countShiftCodes(ch)
+		r.offset = r.offset + int64(r.runeSize+r.shiftSize)
+	}
+	return ch, err
+}
+
+// countShiftCodes returns the size of the shift codes enclosing a run of double-byte characters.
+// Both the shift-out and the shift-in are counted at the beginning of the run since every field
+// must end in the single-byte mode.
+func (r *Reader) countShiftCodes(ch rune) int {
+	if !text.IsShiftEncoding(r.Encoding) {
+		return 0
+	}
+
+	r.prevShifted = r.shifted
+	if text.RuneByteSize(ch, r.Encoding) < 2 {
+		r.shifted = false
+		return 0
+	}
+	if r.shifted {
+		return 0
+	}
+	r.shifted = true
+	return 2
+}
+
+func (r *Reader) unreadRune() error {
+	if err := r.reader.UnreadRune(); err != nil {
+		return err
+	}
+	r.offset = r.offset - int64(r.runeSize+r.shiftSize)
+	r.shifted = r.prevShifted
+	return nil
+}
+
+func (r *Reader) parseRecord(withoutNull bool, reuse bool) ([]text.RawText, error) {
+	if r.raw == nil && r.Framing != LineFraming {
+		r.raw = bufio.NewReader(r.tap)
+		r.offset = 0
+	}
+	if r.raw != nil {
+		return r.parseBytesRecord(withoutNull, reuse)
+	}
+
+	r.tap.Enabled = r.KeepSource
+	r.recordOffset = r.offset
+	r.recordLine = r.line
+	if r.KeepSource {
+		r.tap.Discard(r.recordOffset)
+	}
+
+	var record []text.RawText
+	if reuse {
+		if cap(r.lastRecord) < len(r.DelimiterPositions) {
+			r.lastRecord = make([]text.RawText, len(r.DelimiterPositions))
+		}
+		record = r.lastRecord[:len(r.DelimiterPositions)]
+		r.recordBuf = r.recordBuf[:0]
+		r.fieldStartPos = r.fieldStartPos[:0]
+	} else {
+		record = make([]text.RawText, len(r.DelimiterPositions))
+	}
 	recordPos := 0
 	delimiterPos := 0
 
@@ -85,8 +273,9 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 		delimiterPos = endPos
 
 		r.buf.Reset()
+		r.shifted = false
 		for !lineEnd && recordPos < delimiterPos {
-			c, _, err := r.reader.ReadRune()
+			c, err := r.readRune()
 
 			if err != nil {
 				if err != io.EOF || recordPos < 1 {
@@ -97,11 +286,11 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 			} else {
 				switch c {
 				case '\r':
-					c2, _, _ := r.reader.ReadRune()
+					c2, _ := r.readRune()
 					if c2 == '\n' {
 						lineBreak = text.CRLF
 					} else {
-						if err = r.reader.UnreadRune(); err != nil {
+						if err = r.unreadRune(); err != nil {
 							return nil, err
 						}
 						lineBreak = text.CR
@@ -111,22 +300,50 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 					lineBreak = text.LF
 				}
 				if c == '\n' {
+					r.line++
 					lineEnd = true
 					continue
 				}
 			}
 
-			recordPos = recordPos + text.RuneByteSize(c, r.Encoding)
+			size := r.Unit.RuneSize(c, r.Encoding)
+			if r.Unit.PositionUnit == ByteUnit {
+				size = size + r.shiftSize
+			}
+			recordPos = recordPos + size
 
 			if delimiterPos < recordPos {
-				return nil, errors.New("cannot delimit lines in a byte array of a character")
+				return nil, &text.ParseError{
+					Kind:    text.CharacterBoundaryError,
+					Line:    r.line,
+					Column:  recordPos - size + 1,
+					Offset:  r.offset,
+					Record:  r.record,
+					Field:   i,
+					Message: "cannot delimit lines in a byte array of a character",
+				}
 			}
 
 			r.buf.WriteRune(c)
 		}
 
 		b := r.buf.Bytes()
-		b = bytes.TrimSpace(b)
+		if r.schema != nil && i < len(r.schema.Fields) {
+			b = r.schema.Fields[i].trim(b)
+		} else {
+			b = bytes.TrimSpace(b)
+		}
+		if 0 < len(r.NullTokens) && r.NullTokens.Match(b) {
+			b = b[:0]
+		} else if r.schema != nil && i < len(r.schema.Fields) && 0 < len(r.schema.Fields[i].NullToken) && string(b) == r.schema.Fields[i].NullToken {
+			b = b[:0]
+		}
+
+		if reuse {
+			r.fieldStartPos = append(r.fieldStartPos, len(r.recordBuf))
+			r.recordBuf = append(r.recordBuf, b...)
+			continue
+		}
 
 		if len(b) < 1 {
 			if withoutNull {
@@ -141,7 +358,7 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 
 	if !r.SingleLine && !lineEnd {
 		for {
-			c, _, err := r.reader.ReadRune()
+			c, err := r.readRune()
 			if err != nil {
 				if err != io.EOF || recordPos < 1 {
 					return nil, err
@@ -150,11 +367,11 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 			}
 			switch c {
 			case '\r':
-				c2, _, _ := r.reader.ReadRune()
+				c2, _ := r.readRune()
 				if c2 == '\n' {
 					lineBreak = text.CRLF
 				} else {
-					if err = r.reader.UnreadRune(); err != nil {
+					if err = r.unreadRune(); err != nil {
 						return nil, err
 					}
 					lineBreak = text.CR
@@ -164,6 +381,7 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 				lineBreak = text.LF
 			}
 			if c == '\n' {
+				r.line++
 				lineEnd = true
 				break
 			}
@@ -174,6 +392,383 @@ func (r *Reader) parseRecord(withoutNull bool) ([]text.RawText, error) {
 	if r.DetectedLineBreak == "" {
 		r.DetectedLineBreak = lineBreak
 	}
+	r.record++
+
+	if reuse {
+		r.setBufferedFields(record, withoutNull)
+	}
+
+	return record, nil
+}
+
+func (r *Reader) setBufferedFields(record []text.RawText, withoutNull bool) {
+	var endPos int
+	for i, pos := range r.fieldStartPos {
+		if i == len(r.fieldStartPos)-1 {
+			endPos = len(r.recordBuf)
+		} else {
+			endPos = r.fieldStartPos[i+1]
+		}
+
+		if pos == endPos {
+			if withoutNull {
+				record[i] = text.RawText{}
+			} else {
+				record[i] = nil
+			}
+		} else {
+			record[i] = r.recordBuf[pos:endPos:endPos]
+		}
+	}
+}
+
+func (r *Reader) parseBytesRecord(withoutNull bool, reuse bool) ([]text.RawText, error) {
+	if r.offset < 1 {
+		r.skipBOM()
+	}
+
+	r.tap.Enabled = r.KeepSource
+	r.recordOffset = r.offset
+	r.recordLine = r.line
+	if r.KeepSource {
+		r.tap.Discard(r.recordOffset)
+	}
+
+	var b []byte
+	var err error
+	if r.Framing == LineFraming {
+		b, err = r.readFixedBytes()
+	} else {
+		b, err = r.readFrame()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var record []text.RawText
+	if reuse {
+		if cap(r.lastRecord) < len(r.DelimiterPositions) {
+			r.lastRecord = make([]text.RawText, len(r.DelimiterPositions))
+		}
+		record = r.lastRecord[:len(r.DelimiterPositions)]
+	} else {
+		record = make([]text.RawText, len(r.DelimiterPositions))
+	}
+	r.recordBuf = r.recordBuf[:0]
+	r.fieldStartPos = r.fieldStartPos[:0]
 
+	if r.schema != nil && r.schema.HasDecimalFormats() {
+		err = r.decodeFields(b)
+	} else {
+		err = r.splitFields(b)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.setBufferedFields(record, withoutNull)
+	if !reuse {
+		for i := range record {
+			if 0 < len(record[i]) {
+				record[i] = append(text.RawText(nil), record[i]...)
+			}
+		}
+	}
+
+	r.record++
 	return record, nil
 }
+
+// readFixedBytes reads a record of the length of the schema followed by an optional line break.
+func (r *Reader) readFixedBytes() ([]byte, error) {
+	size := r.DelimiterPositions.Last()
+	if cap(r.rawBuf) < size {
+		r.rawBuf = make([]byte, size)
+	}
+	b := r.rawBuf[:size]
+
+	n, err := io.ReadFull(r.raw, b)
+	r.offset = r.offset + int64(n)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, &text.ParseError{
+				Kind:    text.DataFormatError,
+				Line:    r.line,
+				Column:  n + 1,
+				Offset:  r.offset,
+				Record:  r.record,
+				Field:   len(r.schema.Fields) - 1,
+				Message: fmt.Sprintf("record is shorter than %d bytes", size),
+			}
+		}
+		return nil, err
+	}
+
+	if !r.SingleLine {
+		if err = r.skipRecordSeparator(); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// readFrame reads a record framed by a Record Descriptor Word or a length prefix.
+func (r *Reader) readFrame() ([]byte, error) {
+	var size int
+	var err error
+
+	switch r.Framing {
+	case RDWFraming:
+		if r.BlockDescriptor && r.blockRemaining < 1 {
+			var bdw []byte
+			if bdw, err = r.readFrameBytes(4, true); err != nil {
+				return nil, err
+			}
+			length := int(binary.BigEndian.Uint16(bdw))
+			if bdw[0]&0x80 != 0 {
+				length = int(binary.BigEndian.Uint32(bdw) & 0x7fffffff)
+			}
+			if length < 4 {
+				return nil, r.frameError(fmt.Sprintf("invalid block descriptor word: % x", bdw))
+			}
+			r.blockRemaining = length - 4
+		}
+
+		var rdw []byte
+		if rdw, err = r.readFrameBytes(4, !r.BlockDescriptor); err != nil {
+			return nil, err
+		}
+		length := int(binary.BigEndian.Uint16(rdw))
+		if length < 4 || rdw[2] != 0 || rdw[3] != 0 {
+			return nil, r.frameError(fmt.Sprintf("invalid record descriptor word: % x", rdw))
+		}
+		if r.BlockDescriptor {
+			if r.blockRemaining < length {
+				return nil, r.frameError(fmt.Sprintf("record length %d exceeds the block", length))
+			}
+			r.blockRemaining = r.blockRemaining - length
+		}
+		size = length - 4
+	case LengthPrefixFraming:
+		var prefix []byte
+		if prefix, err = r.readFrameBytes(r.LengthPrefixSize, true); err != nil {
+			return nil, err
+		}
+		for _, c := range prefix {
+			if c < '0' || '9' < c {
+				return nil, r.frameError(fmt.Sprintf("invalid length prefix: %q", prefix))
+			}
+		}
+		size, _ = strconv.Atoi(string(prefix))
+	default:
+		return nil, errors.New(fmt.Sprintf("invalid framing: %d", r.Framing))
+	}
+
+	b, err := r.readFrameBytes(size, false)
+	if err != nil {
+		return nil, err
+	}
+	r.line++
+	return b, nil
+}
+
+// readFrameBytes reads n bytes of a frame. If atStart is true, io.EOF is returned when no bytes remain.
+func (r *Reader) readFrameBytes(n int, atStart bool) ([]byte, error) {
+	if cap(r.rawBuf) < n {
+		r.rawBuf = make([]byte, n)
+	}
+	b := r.rawBuf[:n]
+
+	read, err := io.ReadFull(r.raw, b)
+	r.offset = r.offset + int64(read)
+	if err != nil {
+		if err == io.EOF && atStart {
+			return nil, err
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, r.frameError(fmt.Sprintf("record is shorter than %d bytes", n))
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *Reader) frameError(message string) error {
+	return &text.ParseError{
+		Kind:    text.DataFormatError,
+		Line:    r.line,
+		Column:  1,
+		Offset:  r.recordOffset,
+		Record:  r.record,
+		Message: message,
+	}
+}
+
+// decodeFields decodes the fields of the schema in the bytes of a record.
+// Fields beyond the end of the record are read as NULL.
+func (r *Reader) decodeFields(b []byte) error {
+	decodeEnc, _, _ := text.SeekEncoding(r.Encoding)
+	for i, f := range r.schema.Fields {
+		var value []byte
+		var err error
+
+		switch {
+		case len(b) <= f.Start:
+		case len(b) < f.End && f.Format != TextFormat:
+			err = errors.New(fmt.Sprintf("record ends in the field of %d bytes", f.Length))
+		case len(b) < f.End:
+			value, err = f.decode(b[f.Start:], decodeEnc)
+		default:
+			value, err = f.decode(b[f.Start:f.End], decodeEnc)
+		}
+		if err != nil {
+			return &text.ParseError{
+				Kind:    text.DataFormatError,
+				Line:    r.recordLine,
+				Column:  f.Start + 1,
+				Offset:  r.recordOffset + int64(f.Start),
+				Record:  r.record,
+				Field:   i,
+				Message: fmt.Sprintf("field %s: %s", f.Name, err.Error()),
+			}
+		}
+		if 0 < len(r.NullTokens) && r.NullTokens.Match(value) {
+			value = value[:0]
+		} else if 0 < len(f.NullToken) && string(value) == f.NullToken {
+			value = value[:0]
+		}
+
+		r.fieldStartPos = append(r.fieldStartPos, len(r.recordBuf))
+		r.recordBuf = append(r.recordBuf, value...)
+	}
+	return nil
+}
+
+// splitFields decodes the bytes of a framed record and splits them by the delimiter positions.
+// Fields beyond the end of the record are read as NULL.
+func (r *Reader) splitFields(b []byte) error {
+	decodeEnc, _, _ := text.SeekEncoding(r.Encoding)
+	s, err := text.Decode(b, decodeEnc)
+	if err != nil {
+		return err
+	}
+
+	recordPos := 0
+	delimiterPos := 0
+	idx := 0
+	for i, endPos := range r.DelimiterPositions {
+		if endPos < 0 || endPos <= delimiterPos {
+			return errors.New(fmt.Sprintf("invalid delimiter position: %s", r.DelimiterPositions))
+		}
+		delimiterPos = endPos
+
+		start := idx
+		shifted := false
+		for idx < len(s) && recordPos < delimiterPos {
+			c, n := utf8.DecodeRune(s[idx:])
+			size := r.Unit.RuneSize(c, r.Encoding)
+			if r.Unit.PositionUnit == ByteUnit && text.IsShiftEncoding(r.Encoding) {
+				if size < 2 {
+					shifted = false
+				} else if !shifted {
+					size = size + 2
+					shifted = true
+				}
+			}
+			recordPos = recordPos + size
+
+			if delimiterPos < recordPos {
+				return &text.ParseError{
+					Kind:    text.CharacterBoundaryError,
+					Line:    r.recordLine,
+					Column:  recordPos - size + 1,
+					Offset:  r.recordOffset,
+					Record:  r.record,
+					Field:   i,
+					Message: "cannot delimit lines in a byte array of a character",
+				}
+			}
+			idx = idx + n
+		}
+
+		value := s[start:idx]
+		if r.schema != nil && i < len(r.schema.Fields) {
+			value = r.schema.Fields[i].trim(value)
+		} else {
+			value = bytes.TrimSpace(value)
+		}
+		if 0 < len(r.NullTokens) && r.NullTokens.Match(value) {
+			value = value[:0]
+		} else if r.schema != nil && i < len(r.schema.Fields) && 0 < len(r.schema.Fields[i].NullToken) && string(value) == r.schema.Fields[i].NullToken {
+			value = value[:0]
+		}
+
+		r.fieldStartPos = append(r.fieldStartPos, len(r.recordBuf))
+		r.recordBuf = append(r.recordBuf, value...)
+	}
+	return nil
+}
+
+func (r *Reader) skipBOM() {
+	var bom string
+	switch r.Encoding {
+	case text.UTF8M:
+		bom = text.UTF8BOM
+	case text.UTF16BEM:
+		bom = text.UTF16BEBOM
+	case text.UTF16LEM:
+		bom = text.UTF16LEBOM
+	default:
+		return
+	}
+
+	if b, err := r.raw.Peek(len(bom)); err == nil && string(b) == bom {
+		_, _ = r.raw.Discard(len(bom))
+		r.offset = int64(len(bom))
+	}
+}
+
+func (r *Reader) skipRecordSeparator() error {
+	if len(r.RecordSeparator) < 1 {
+		return nil
+	}
+
+	sep := r.RecordSeparator
+	if len(sep) < len(r.crlf) && bytes.HasSuffix(r.crlf, sep) {
+		if b, _ := r.raw.Peek(len(r.crlf)); bytes.Equal(b, r.crlf) {
+			sep = r.crlf
+		}
+	}
+
+	b, err := r.raw.Peek(len(sep))
+	if len(b) < 1 && err == io.EOF {
+		return nil
+	}
+	if !bytes.Equal(b, sep) {
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return &text.ParseError{
+			Kind:    text.DataFormatError,
+			Line:    r.line,
+			Column:  r.DelimiterPositions.Last() + 1,
+			Offset:  r.offset,
+			Record:  r.record,
+			Field:   len(r.schema.Fields) - 1,
+			Message: fmt.Sprintf("record is not followed by the record separator % x", r.RecordSeparator),
+		}
+	}
+
+	_, _ = r.raw.Discard(len(b))
+	r.offset = r.offset + int64(len(b))
+	r.line++
+	if r.DetectedLineBreak == "" {
+		if s, err := text.Decode(b, r.Encoding); err == nil {
+			switch lb := text.LineBreak(s); lb {
+			case text.CR, text.LF, text.CRLF:
+				r.DetectedLineBreak = lb
+			}
+		}
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package fixedlen
 
 import (
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -72,6 +74,7 @@ var readerReadAllTests = []struct {
 	WithoutNull        bool
 	SingleLine         bool
 	Encoding           text.Encoding
+	Unit               Unit
 	Output             [][]text.RawText
 	ExpectLineBreak    text.LineBreak
 	Error              string
@@ -245,7 +248,7 @@ var readerReadAllTests = []struct {
 		DelimiterPositions: []int{5, 10, 15},
 		WithoutNull:        false,
 		Encoding:           text.UTF8,
-		Error:              "cannot delimit lines in a byte array of a character",
+		Error:              "line 1, column 9: cannot delimit lines in a byte array of a character",
 	},
 	{
 		Name:               "ReadAll from SJIS Text with position error",
@@ -253,7 +256,27 @@ var readerReadAllTests = []struct {
 		DelimiterPositions: []int{5, 10, 15},
 		WithoutNull:        false,
 		Encoding:           text.SJIS,
-		Error:              "cannot delimit lines in a byte array of a character",
+		Error:              "line 1, column 10: cannot delimit lines in a byte array of a character",
+	},
+	{
+		Name:               "ReadAll from CP930 Text",
+		Input:              "\x0e\x45\x62\x45\x66\x0f\x0e\x48\xe7\x0f\x25\xc1\x40\x40\x40\x40\x40\x0e\x48\xe7\x0f",
+		DelimiterPositions: []int{6, 10},
+		WithoutNull:        false,
+		Encoding:           text.CP930,
+		Output: [][]text.RawText{
+			{text.RawText("日本"), text.RawText("語")},
+			{text.RawText("A"), text.RawText("語")},
+		},
+		ExpectLineBreak: text.LF,
+	},
+	{
+		Name:               "ReadAll from CP930 Text with position error",
+		Input:              "\x0e\x45\x62\x45\x66\x0f\x0e\x48\xe7\x0f",
+		DelimiterPositions: []int{5, 10},
+		WithoutNull:        false,
+		Encoding:           text.CP930,
+		Error:              "line 1, column 5: cannot delimit lines in a byte array of a character",
 	},
 	{
 		Name:               "UTF-8 with BOM",
@@ -280,6 +303,50 @@ var readerReadAllTests = []struct {
 		},
 		ExpectLineBreak: "",
 	},
+	{
+		Name:               "ReadAll in Runes",
+		Input:              "日本語abc\nあいうえお",
+		DelimiterPositions: []int{2, 6},
+		Encoding:           text.UTF8,
+		Unit:               Unit{PositionUnit: RuneUnit},
+		Output: [][]text.RawText{
+			{text.RawText("日本"), text.RawText("語abc")},
+			{text.RawText("あい"), text.RawText("うえお")},
+		},
+		ExpectLineBreak: text.LF,
+	},
+	{
+		Name:               "ReadAll in Display Width",
+		Input:              "日本 abc  x\nab   cdef y\n",
+		DelimiterPositions: []int{5, 10, 11},
+		Encoding:           text.UTF8,
+		Unit:               Unit{PositionUnit: WidthUnit},
+		Output: [][]text.RawText{
+			{text.RawText("日本"), text.RawText("abc"), text.RawText("x")},
+			{text.RawText("ab"), text.RawText("cdef"), text.RawText("y")},
+		},
+		ExpectLineBreak: text.LF,
+	},
+	{
+		Name:               "ReadAll in Display Width with Ambiguous Width Characters",
+		Input:              "○○abc\nabcdefg",
+		DelimiterPositions: []int{4, 7},
+		Encoding:           text.UTF8,
+		Unit:               Unit{PositionUnit: WidthUnit, EastAsianEncoding: true},
+		Output: [][]text.RawText{
+			{text.RawText("○○"), text.RawText("abc")},
+			{text.RawText("abcd"), text.RawText("efg")},
+		},
+		ExpectLineBreak: text.LF,
+	},
+	{
+		Name:               "ReadAll in Display Width with Position Error",
+		Input:              "abc日本\n",
+		DelimiterPositions: []int{4, 7},
+		Encoding:           text.UTF8,
+		Unit:               Unit{PositionUnit: WidthUnit},
+		Error:              "line 1, column 4: cannot delimit lines in a byte array of a character",
+	},
 }
 
 func TestFixedLengthReader_ReadAll(t *testing.T) {
@@ -296,6 +363,7 @@ func TestFixedLengthReader_ReadAll(t *testing.T) {
 
 		r.WithoutNull = v.WithoutNull
 		r.SingleLine = v.SingleLine
+		r.Unit = v.Unit
 
 		records, err := r.ReadAll()
 
@@ -318,3 +386,304 @@ func TestFixedLengthReader_ReadAll(t *testing.T) {
 		}
 	}
 }
+
+func TestFixedLengthReader_ReadWithReuseRecord(t *testing.T) {
+	input := "ab cde  fghi\n   xyz  uvw \n"
+	output := [][]text.RawText{
+		{text.RawText("ab"), text.RawText("cde"), text.RawText("fghi")},
+		{nil, text.RawText("xyz"), text.RawText("uvw")},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), []int{3, 8, 12}, text.UTF8)
+	r.ReuseRecord = true
+
+	records := make([][]text.RawText, 0, len(output))
+	var first []text.RawText
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+
+		if first == nil {
+			first = record
+		} else if &first[0] != &record[0] {
+			t.Errorf("record is not reused")
+		}
+
+		cp := make([]text.RawText, len(record))
+		for i := range record {
+			if record[i] != nil {
+				cp[i] = append(text.RawText{}, record[i]...)
+			}
+		}
+		records = append(records, cp)
+	}
+
+	if !reflect.DeepEqual(records, output) {
+		t.Errorf("records = %q, want %q", records, output)
+	}
+}
+
+var readerReadBenchmarkText = strings.Repeat("aaaaaa  bbbbbb  cccccc\n", 10000)
+
+func BenchmarkFixedLengthReader_Read(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadBenchmarkText)
+		reader, _ := NewReader(r, []int{6, 14, 22}, text.UTF8)
+		for {
+			if _, err := reader.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkFixedLengthReader_ReadWithReuseRecord(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadBenchmarkText)
+		reader, _ := NewReader(r, []int{6, 14, 22}, text.UTF8)
+		reader.ReuseRecord = true
+		for {
+			if _, err := reader.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func TestFixedLengthReader_ReadAllWithNullTokens(t *testing.T) {
+	input := "NA   abc  \n  a    NA\n"
+	output := [][]text.RawText{
+		{nil, text.RawText("abc")},
+		{text.RawText("a"), nil},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), []int{5, 10}, text.UTF8)
+	r.NullTokens = text.NullTokens{"NA"}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(records, output) {
+		t.Errorf("records = %q, want %q", records, output)
+	}
+}
+
+func TestFixedLengthReader_Source(t *testing.T) {
+	input := "abc" + string([]byte{0x93, 0xfa}) + "de\r\nfgh  ij\r\n"
+	output := []text.Source{
+		{Bytes: []byte("abc" + string([]byte{0x93, 0xfa}) + "de\r\n"), Text: "abc日de\r\n", Line: 1, Offset: 0},
+		{Bytes: []byte("fgh  ij\r\n"), Text: "fgh  ij\r\n", Line: 2, Offset: 9},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), []int{3, 7}, text.SJIS)
+	r.KeepSource = true
+
+	for i, expect := range output {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+		if result := r.Source(); !reflect.DeepEqual(result, expect) {
+			t.Errorf("source %d = %#v, want %#v", i, result, expect)
+		}
+	}
+}
+
+func TestFixedLengthReader_ReadParseError(t *testing.T) {
+	input := "abcdef\nab日本\n"
+	expect := &text.ParseError{
+		Kind:    text.CharacterBoundaryError,
+		Line:    2,
+		Column:  3,
+		Offset:  12,
+		Record:  1,
+		Field:   0,
+		Message: "cannot delimit lines in a byte array of a character",
+	}
+
+	r, _ := NewReader(strings.NewReader(input), []int{4, 8}, text.UTF8)
+	_, err := r.ReadAll()
+
+	var perr *text.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *text.ParseError", err)
+	}
+	if !reflect.DeepEqual(perr, expect) {
+		t.Errorf("error = %#v, want %#v", perr, expect)
+	}
+}
+
+var readerReadFramedTests = []struct {
+	Name             string
+	Input            string
+	Positions        []int
+	Schema           *Schema
+	Framing          Framing
+	BlockDescriptor  bool
+	LengthPrefixSize int
+	Encoding         text.Encoding
+	Output           [][]text.RawText
+	Error            string
+}{
+	{
+		Name:      "RDW",
+		Input:     "\x00\x0c\x00\x00abc  def" + "\x00\x07\x00\x00gh ",
+		Positions: []int{5, 8},
+		Framing:   RDWFraming,
+		Output: [][]text.RawText{
+			{text.RawText("abc"), text.RawText("def")},
+			{text.RawText("gh"), nil},
+		},
+	},
+	{
+		Name:            "RDW with Block Descriptor",
+		Input:           "\x00\x14\x00\x00" + "\x00\x08\x00\x00abcd" + "\x00\x08\x00\x00efgh" + "\x00\x0a\x00\x00" + "\x00\x06\x00\x00ij",
+		Positions:       []int{2, 4},
+		Framing:         RDWFraming,
+		BlockDescriptor: true,
+		Output: [][]text.RawText{
+			{text.RawText("ab"), text.RawText("cd")},
+			{text.RawText("ef"), text.RawText("gh")},
+			{text.RawText("ij"), nil},
+		},
+	},
+	{
+		Name:      "RDW CP930",
+		Input:     "\x00\x0d\x00\x00\xc1\x0e\x45\x41\x4b\xce\x0f\xc2\xc3",
+		Positions: []int{1, 7, 9},
+		Framing:   RDWFraming,
+		Encoding:  text.CP930,
+		Output: [][]text.RawText{
+			{text.RawText("A"), text.RawText("一丁"), text.RawText("BC")},
+		},
+	},
+	{
+		Name:     "RDW Decimal Formats",
+		Input:    "\x00\x0b\x00\x00\xf1\xf2\xd3\x12\x34\x5d\xc1" + "\x00\x07\x00\x00\xf4\xf5\xf6",
+		Framing:  RDWFraming,
+		Encoding: text.CP037,
+		Schema: &Schema{Fields: []SchemaField{
+			{Name: "id", Length: 3, Format: ZonedDecimalFormat},
+			{Name: "amount", Start: 3, Length: 3, Format: PackedDecimalFormat, Scale: 2},
+			{Name: "code", Start: 6, Length: 2},
+		}},
+		Output: [][]text.RawText{
+			{text.RawText("-123"), text.RawText("-123.45"), text.RawText("A")},
+			{text.RawText("456"), nil, nil},
+		},
+	},
+	{
+		Name:      "Invalid RDW",
+		Input:     "\x00\x0c\x00\x00abc  def" + "\x00\x07\x01\x00gh ",
+		Positions: []int{5, 8},
+		Framing:   RDWFraming,
+		Error:     "line 2, column 1: invalid record descriptor word: 00 07 01 00",
+	},
+	{
+		Name:      "Truncated RDW Record",
+		Input:     "\x00\x0c\x00\x00abc",
+		Positions: []int{5, 8},
+		Framing:   RDWFraming,
+		Error:     "line 1, column 1: record is shorter than 8 bytes",
+	},
+	{
+		Name:            "Record Exceeds Block",
+		Input:           "\x00\x0a\x00\x00" + "\x00\x08\x00\x00abcd",
+		Positions:       []int{2, 4},
+		Framing:         RDWFraming,
+		BlockDescriptor: true,
+		Error:           "line 1, column 1: record length 8 exceeds the block",
+	},
+	{
+		Name:      "Length Prefix",
+		Input:     "0008abc  def" + "0003gh " + "0000",
+		Positions: []int{5, 8},
+		Framing:   LengthPrefixFraming,
+		Output: [][]text.RawText{
+			{text.RawText("abc"), text.RawText("def")},
+			{text.RawText("gh"), nil},
+			{nil, nil},
+		},
+	},
+	{
+		Name:             "Length Prefix Size",
+		Input:            "06日本語" + "06abcdef",
+		Positions:        []int{2, 4, 6},
+		Framing:          LengthPrefixFraming,
+		LengthPrefixSize: 2,
+		Encoding:         text.SJIS,
+		Output: [][]text.RawText{
+			{text.RawText("日"), text.RawText("本"), text.RawText("語")},
+			{text.RawText("ab"), text.RawText("cd"), text.RawText("ef")},
+		},
+	},
+	{
+		Name:      "Invalid Length Prefix",
+		Input:     "00x8abc  def",
+		Positions: []int{5, 8},
+		Framing:   LengthPrefixFraming,
+		Error:     "line 1, column 1: invalid length prefix: \"00x8\"",
+	},
+	{
+		Name:      "Character Boundary",
+		Input:     "0005ab日",
+		Positions: []int{3, 5},
+		Framing:   LengthPrefixFraming,
+		Error:     "line 1, column 3: cannot delimit lines in a byte array of a character",
+	},
+}
+
+func TestFixedLengthReader_ReadFramed(t *testing.T) {
+	for _, v := range readerReadFramedTests {
+		enc := v.Encoding
+		if enc == text.AUTO {
+			enc = text.UTF8
+		}
+		input := v.Input
+		if enc == text.SJIS {
+			b, _ := text.Encode([]byte(input), enc)
+			input = string(b)
+		}
+
+		var r *Reader
+		var err error
+		if v.Schema != nil {
+			r, err = NewSchemaReader(strings.NewReader(input), v.Schema, enc)
+		} else {
+			r, err = NewReader(strings.NewReader(input), v.Positions, enc)
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		r.Framing = v.Framing
+		r.BlockDescriptor = v.BlockDescriptor
+		if 0 < v.LengthPrefixSize {
+			r.LengthPrefixSize = v.LengthPrefixSize
+		}
+
+		records, err := r.ReadAll()
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %q, want %q", v.Name, records, v.Output)
+		}
+	}
+}
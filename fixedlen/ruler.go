@@ -0,0 +1,251 @@
+package fixedlen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/color"
+)
+
+// BoundaryIssue is a field boundary that does not fit a sample line.
+type BoundaryIssue struct {
+	// Line is the line number in the sample, and Position is the delimiter position.
+	Line     int
+	Position int
+	Message  string
+}
+
+func (i BoundaryIssue) String() string {
+	return fmt.Sprintf("line %d, position %d: %s", i.Line, i.Position, i.Message)
+}
+
+// Ruler renders sample lines under a column ruler with the boundaries of the fields
+// to check delimiter positions.
+//
+// Every character occupies as many cells as its size in the unit. Lines are not aligned with
+// the ruler if a character is displayed wider than its size, such as full-width characters in RuneUnit.
+type Ruler struct {
+	Encoding text.Encoding
+
+	// Unit is the unit of the delimiter positions and the ruler.
+	Unit Unit
+
+	// SampleLines is the maximum number of lines rendered. All lines are rendered if 0.
+	SampleLines int
+
+	// FieldEffectors color the fields in turn. Fields are not colored if empty.
+	FieldEffectors []*color.Effector
+	// IssueEffector colors the marks of boundary issues.
+	IssueEffector *color.Effector
+
+	positions DelimiterPositions
+	reader    *bufio.Reader
+	lineBuf   bytes.Buffer
+}
+
+func NewRuler(r io.Reader, positions DelimiterPositions, enc text.Encoding) (*Ruler, error) {
+	decoder, err := text.GetTransformDecoder(r, enc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ruler{
+		Encoding:  enc,
+		positions: positions,
+		reader:    bufio.NewReader(decoder),
+	}, nil
+}
+
+type rulerLine struct {
+	text    string
+	cells   int
+	marks   []int
+	message string
+}
+
+// Render returns the ruler followed by the sample lines, and the issues of boundaries falling inside values
+// or splitting characters. Each line with issues is followed by a line marking the boundaries.
+func (ru *Ruler) Render() (string, []BoundaryIssue, error) {
+	var issues []BoundaryIssue
+	lines := make([]rulerLine, 0, 10)
+
+	width := ru.positions.Last()
+	for ru.SampleLines < 1 || len(lines) < ru.SampleLines {
+		s, err := ru.readLine()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", nil, err
+		}
+
+		line, lineIssues := ru.renderLine(s, len(lines)+1)
+		lines = append(lines, line)
+		issues = append(issues, lineIssues...)
+		if width < line.cells {
+			width = line.cells
+		}
+	}
+
+	var buf strings.Builder
+	ru.writeRuler(&buf, width)
+
+	for _, line := range lines {
+		buf.WriteString(line.text)
+		buf.WriteByte('\n')
+
+		if 0 < len(line.marks) {
+			marks := []rune(strings.Repeat(" ", line.marks[len(line.marks)-1]+1))
+			for _, m := range line.marks {
+				marks[m] = '^'
+			}
+			buf.WriteString(ru.renderIssue(string(marks)))
+			buf.WriteString(" ")
+			buf.WriteString(line.message)
+			buf.WriteByte('\n')
+		}
+	}
+
+	return buf.String(), issues, nil
+}
+
+func (ru *Ruler) readLine() (string, error) {
+	ru.lineBuf.Reset()
+
+	for {
+		line, isPrefix, err := ru.reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		ru.lineBuf.Write(line)
+		if !isPrefix {
+			break
+		}
+	}
+	return ru.lineBuf.String(), nil
+}
+
+func (ru *Ruler) writeRuler(buf *strings.Builder, width int) {
+	for i := 1; i <= width; i++ {
+		if i%10 == 0 {
+			buf.WriteByte(byte('0' + i/10%10))
+		} else {
+			buf.WriteByte(' ')
+		}
+	}
+	buf.WriteByte('\n')
+
+	for i := 1; i <= width; i++ {
+		buf.WriteByte(byte('0' + i%10))
+	}
+	buf.WriteByte('\n')
+
+	boundaries := []rune(strings.Repeat(" ", ru.positions.Last()+1))
+	boundaries[0] = '|'
+	for _, p := range ru.positions {
+		if 0 <= p && p < len(boundaries) {
+			boundaries[p] = '|'
+		}
+	}
+	buf.WriteString(string(boundaries))
+	buf.WriteByte('\n')
+}
+
+func (ru *Ruler) renderLine(s string, lineNumber int) (rulerLine, []BoundaryIssue) {
+	var issues []BoundaryIssue
+	var marks []int
+	var messages []string
+
+	addIssue := func(position int, message string) {
+		issues = append(issues, BoundaryIssue{Line: lineNumber, Position: position, Message: message})
+		marks = append(marks, position)
+		messages = append(messages, fmt.Sprintf("%d: %s", position, message))
+	}
+
+	var buf strings.Builder
+	var segment strings.Builder
+	field := 0
+	flush := func() {
+		buf.WriteString(ru.renderField(segment.String(), field))
+		segment.Reset()
+	}
+
+	pos := 0
+	next := 0
+	shifted := false
+	prevSpace := true
+	countShiftCodes := ru.Unit.PositionUnit == ByteUnit && text.IsShiftEncoding(ru.Encoding)
+
+	for _, c := range s {
+		size := ru.Unit.RuneSize(c, ru.Encoding)
+		if countShiftCodes {
+			if size < 2 {
+				shifted = false
+			} else if !shifted {
+				size = size + 2
+				shifted = true
+			}
+		}
+		space := unicode.IsSpace(c)
+
+		for next < len(ru.positions) && ru.positions[next] <= pos {
+			if ru.positions[next] == pos && 0 < pos && !prevSpace && !space {
+				addIssue(pos, "boundary falls inside a value")
+			}
+			flush()
+			next++
+			field = next
+		}
+
+		split := false
+		for next < len(ru.positions) && ru.positions[next] < pos+size {
+			addIssue(ru.positions[next], "boundary splits a character")
+			next++
+			split = true
+		}
+
+		if unicode.IsControl(c) {
+			c = '.'
+		}
+		segment.WriteRune(c)
+		if w := text.RuneWidth(c, ru.Unit.EastAsianEncoding, ru.Unit.CountDiacriticalSign, ru.Unit.CountFormatCode); w < size {
+			segment.WriteString(strings.Repeat(" ", size-w))
+		}
+
+		if split {
+			flush()
+			field = next
+		}
+
+		pos = pos + size
+		prevSpace = space
+	}
+	flush()
+
+	return rulerLine{
+		text:    buf.String(),
+		cells:   pos,
+		marks:   marks,
+		message: strings.Join(messages, ", "),
+	}, issues
+}
+
+func (ru *Ruler) renderField(s string, field int) string {
+	if len(s) < 1 || len(ru.FieldEffectors) < 1 || len(ru.positions) <= field {
+		return s
+	}
+	return ru.FieldEffectors[field%len(ru.FieldEffectors)].Render(s)
+}
+
+func (ru *Ruler) renderIssue(s string) string {
+	if ru.IssueEffector == nil {
+		return s
+	}
+	return ru.IssueEffector.Render(s)
+}
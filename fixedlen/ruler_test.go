@@ -0,0 +1,74 @@
+package fixedlen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/color"
+)
+
+func TestRuler_Render(t *testing.T) {
+	input := "abc  def\nabcd日 fg\r\nabcdefgh\n"
+
+	r, err := NewRuler(strings.NewReader(input), []int{5, 8}, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	result, issues, err := r.Render()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	expect := "" +
+		"         1\n" +
+		"1234567890\n" +
+		"|    |  |\n" +
+		"abc  def\n" +
+		"abcd日  fg\n" +
+		"     ^ 5: boundary splits a character\n" +
+		"abcdefgh\n" +
+		"     ^ 5: boundary falls inside a value\n"
+	if result != expect {
+		t.Errorf("result = %q, want %q", result, expect)
+	}
+
+	expectIssues := []BoundaryIssue{
+		{Line: 2, Position: 5, Message: "boundary splits a character"},
+		{Line: 3, Position: 5, Message: "boundary falls inside a value"},
+	}
+	if !reflect.DeepEqual(issues, expectIssues) {
+		t.Errorf("issues = %v, want %v", issues, expectIssues)
+	}
+}
+
+func TestRuler_RenderWithEffectors(t *testing.T) {
+	red := color.NewEffector()
+	red.SetFGColor(color.Red)
+	blue := color.NewEffector()
+	blue.SetFGColor(color.Blue)
+	bold := color.NewEffector()
+	bold.SetEffect(color.Bold)
+
+	input, _ := text.Encode([]byte("AB 日  DE\nFG\n"), text.CP930)
+	r, _ := NewRuler(strings.NewReader(string(input)), []int{3, 5, 8}, text.CP930)
+	r.SampleLines = 1
+	r.FieldEffectors = []*color.Effector{red, blue}
+	r.IssueEffector = bold
+
+	result, _, err := r.Render()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	expect := "" +
+		"         1 \n" +
+		"12345678901\n" +
+		"|  | |  |\n" +
+		red.Render("AB ") + blue.Render("日  ") + red.Render(" ") + " DE\n" +
+		bold.Render("     ^") + " 5: boundary splits a character\n"
+	if result != expect {
+		t.Errorf("result = %q, want %q", result, expect)
+	}
+}
@@ -0,0 +1,442 @@
+package fixedlen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/json"
+)
+
+type FieldFormat int
+
+const (
+	TextFormat FieldFormat = iota
+	ZonedDecimalFormat
+	PackedDecimalFormat
+	BinaryFormat
+)
+
+var FieldFormatLiteral = map[FieldFormat]string{
+	TextFormat:          "text",
+	ZonedDecimalFormat:  "zoned",
+	PackedDecimalFormat: "packed",
+	BinaryFormat:        "binary",
+}
+
+func (f FieldFormat) String() string {
+	return FieldFormatLiteral[f]
+}
+
+// SchemaField defines a field of fixed-length records.
+// Start is zero-based, and End is exclusive. If End is 0, it is calculated from Length.
+type SchemaField struct {
+	Name   string
+	Start  int
+	End    int
+	Length int
+
+	// Alignment is used by Writer for fields written as NotAligned.
+	Alignment text.FieldAlignment
+	// PadChar is used by Writer instead of Writer.PadChar if it is not 0, and is removed by Reader.
+	PadChar byte
+	// Type is checked by Writer for INTEGER, FLOAT and BOOLEAN fields. Values of other types are not checked.
+	Type text.ColumnType
+	// NullToken is read as NULL in addition to blank fields,
+	// and is written for NULL fields instead of Writer.NullToken if it is not empty.
+	NullToken string
+
+	// Format is the representation of the field in records.
+	// Fields other than TextFormat are decoded into decimal strings by Reader,
+	// and require positions in bytes.
+	Format FieldFormat
+	// Scale is the number of digits after the implied decimal point.
+	Scale int
+	// Signed is used by BinaryFormat.
+	Signed bool
+	// SignLeading and SignSeparate are used by ZonedDecimalFormat.
+	SignLeading  bool
+	SignSeparate bool
+}
+
+// Schema defines the fields of fixed-length records in order.
+// Fields must cover records from the beginning without overlaps or gaps.
+type Schema struct {
+	Fields []SchemaField
+	Unit   Unit
+}
+
+// Validate calculates the end positions of the fields and checks the definitions.
+func (s *Schema) Validate() error {
+	if len(s.Fields) < 1 {
+		return errors.New("schema has no fields")
+	}
+
+	names := make(map[string]bool, len(s.Fields))
+	pos := 0
+	for i := range s.Fields {
+		f := &s.Fields[i]
+
+		if len(f.Name) < 1 {
+			return errors.New(fmt.Sprintf("field %d: name is empty", i+1))
+		}
+		if names[f.Name] {
+			return errors.New(fmt.Sprintf("duplicate field: %q", f.Name))
+		}
+		names[f.Name] = true
+
+		if f.End == 0 {
+			f.End = f.Start + f.Length
+		} else if 0 < f.Length && f.End-f.Start != f.Length {
+			return errors.New(fmt.Sprintf("field %s: length %d does not match position %d:%d", f.Name, f.Length, f.Start, f.End))
+		}
+		if f.Start < 0 || f.End <= f.Start {
+			return errors.New(fmt.Sprintf("field %s: invalid position %d:%d", f.Name, f.Start, f.End))
+		}
+		f.Length = f.End - f.Start
+
+		if f.Format != TextFormat {
+			if s.Unit.PositionUnit != ByteUnit {
+				return errors.New(fmt.Sprintf("field %s: %s format requires positions in bytes", f.Name, f.Format))
+			}
+			if f.Scale < 0 {
+				return errors.New(fmt.Sprintf("field %s: invalid scale %d", f.Name, f.Scale))
+			}
+			if f.Format == BinaryFormat && f.Length != 1 && f.Length != 2 && f.Length != 4 && f.Length != 8 {
+				return errors.New(fmt.Sprintf("field %s: invalid binary length %d", f.Name, f.Length))
+			}
+			if f.Format == ZonedDecimalFormat && f.SignSeparate && f.Length < 2 {
+				return errors.New(fmt.Sprintf("field %s: invalid zoned decimal length %d", f.Name, f.Length))
+			}
+		}
+
+		if f.Start < pos {
+			return errors.New(fmt.Sprintf("field %s overlaps field %s", f.Name, s.Fields[i-1].Name))
+		}
+		if pos < f.Start {
+			return errors.New(fmt.Sprintf("field %s: gap from position %d to %d", f.Name, pos, f.Start))
+		}
+		pos = f.End
+	}
+	return nil
+}
+
+// Positions returns the delimiter positions of the fields. The schema must be validated.
+func (s *Schema) Positions() DelimiterPositions {
+	positions := make(DelimiterPositions, len(s.Fields))
+	for i, f := range s.Fields {
+		positions[i] = f.End
+	}
+	return positions
+}
+
+func (f SchemaField) decode(b []byte, enc text.Encoding) ([]byte, error) {
+	var s string
+	var err error
+
+	switch f.Format {
+	case TextFormat:
+		d, err := text.Decode(b, enc)
+		if err != nil {
+			return nil, err
+		}
+		return f.trim(d), nil
+	case ZonedDecimalFormat, PackedDecimalFormat:
+		if isBlank(b) {
+			return nil, nil
+		}
+		if f.Format == ZonedDecimalFormat {
+			s, err = DecodeZonedDecimal(b, f.Scale, f.SignLeading, f.SignSeparate)
+		} else {
+			s, err = DecodePackedDecimal(b, f.Scale)
+		}
+	case BinaryFormat:
+		s, err = DecodeBinaryDecimal(b, f.Signed, f.Scale)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []byte(s), nil
+}
+
+// trim removes spaces and the pad character written by Writer from a text value.
+// The pad character is removed from the beginning of right-aligned fields, from both sides of centered fields,
+// and from the end of the others. A zero is left if a value consists only of zeros for padding.
+func (f SchemaField) trim(b []byte) []byte {
+	b = bytes.TrimSpace(b)
+	if f.PadChar == 0 || f.PadChar == ' ' || len(b) < 1 {
+		return b
+	}
+
+	pad := string(f.PadChar)
+	var trimmed []byte
+	switch f.Alignment {
+	case text.RightAligned:
+		trimmed = bytes.TrimLeft(b, pad)
+	case text.Centering:
+		trimmed = bytes.Trim(b, pad)
+	default:
+		trimmed = bytes.TrimRight(b, pad)
+	}
+	if len(trimmed) < 1 && f.PadChar == '0' {
+		return b[len(b)-1:]
+	}
+	return trimmed
+}
+
+// checkType returns an error if the value is not of the type of the field.
+func (f SchemaField) checkType(s string) error {
+	var err error
+	switch f.Type {
+	case text.IntegerType:
+		_, err = strconv.ParseInt(s, 10, 64)
+	case text.FloatType:
+		if !isNumber(s) {
+			err = strconv.ErrSyntax
+		}
+	case text.BooleanType:
+		_, err = strconv.ParseBool(s)
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("field %s: invalid %s value: %q", f.Name, f.Type, s))
+	}
+	return nil
+}
+
+// isBlank returns true if b consists of spaces in ASCII or EBCDIC, or low-values.
+func isBlank(b []byte) bool {
+	for _, c := range b {
+		if c != ' ' && c != 0x40 && c != 0x00 {
+			return false
+		}
+	}
+	return true
+}
+
+// HasDecimalFormats returns true if the schema has fields other than TextFormat,
+// which make Reader read records as bytes.
+func (s *Schema) HasDecimalFormats() bool {
+	for _, f := range s.Fields {
+		if f.Format != TextFormat {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Schema) Names() []string {
+	names := make([]string, len(s.Fields))
+	for i, f := range s.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// ReadSchema reads a schema written in JSON, and validates it.
+//
+//	{
+//	  "unit": "byte",
+//	  "fields": [
+//	    {"name": "id", "length": 5, "align": "right", "pad": "0", "type": "integer"},
+//	    {"name": "name", "start": 5, "end": 25, "null": "N/A"}
+//	  ]
+//	}
+//
+// "unit" is one of "byte", "rune" and "width". If "start" is omitted, the field starts
+// at the end of the previous field.
+// "format" is one of "text", "zoned", "packed" and "binary", and is used with "scale", "signed",
+// "sign_leading" and "sign_separate".
+// "type" is a column type such as "integer". Values of "integer", "float" and "boolean" fields are checked by Writer.
+func ReadSchema(r io.Reader) (*Schema, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	st, _, err := json.NewDecoder().Decode(string(b))
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := st.(json.Object)
+	if !ok {
+		return nil, errors.New("schema must be an object")
+	}
+
+	schema := &Schema{}
+	var fields json.Array
+	for _, m := range obj.Members {
+		switch m.Key {
+		case "unit":
+			s, err := schemaString(m.Key, m.Value)
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(s) {
+			case "byte":
+				schema.Unit.PositionUnit = ByteUnit
+			case "rune":
+				schema.Unit.PositionUnit = RuneUnit
+			case "width":
+				schema.Unit.PositionUnit = WidthUnit
+			default:
+				return nil, errors.New(fmt.Sprintf("invalid unit: %q", s))
+			}
+		case "east_asian_encoding":
+			if schema.Unit.EastAsianEncoding, err = schemaBool(m.Key, m.Value); err != nil {
+				return nil, err
+			}
+		case "count_diacritical_sign":
+			if schema.Unit.CountDiacriticalSign, err = schemaBool(m.Key, m.Value); err != nil {
+				return nil, err
+			}
+		case "count_format_code":
+			if schema.Unit.CountFormatCode, err = schemaBool(m.Key, m.Value); err != nil {
+				return nil, err
+			}
+		case "fields":
+			if fields, ok = m.Value.(json.Array); !ok {
+				return nil, errors.New("fields must be an array")
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown key: %q", m.Key))
+		}
+	}
+
+	schema.Fields = make([]SchemaField, 0, len(fields))
+	pos := 0
+	for i, v := range fields {
+		f, err := readSchemaField(v, pos)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("field %d: %s", i+1, err.Error()))
+		}
+		schema.Fields = append(schema.Fields, f)
+		pos = f.Start + f.Length
+		if 0 < f.End {
+			pos = f.End
+		}
+	}
+
+	if err = schema.Validate(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func readSchemaField(st json.Structure, start int) (SchemaField, error) {
+	f := SchemaField{Start: start}
+
+	obj, ok := st.(json.Object)
+	if !ok {
+		return f, errors.New("field must be an object")
+	}
+
+	var err error
+	for _, m := range obj.Members {
+		switch m.Key {
+		case "name":
+			f.Name, err = schemaString(m.Key, m.Value)
+		case "start":
+			f.Start, err = schemaInt(m.Key, m.Value)
+		case "end":
+			f.End, err = schemaInt(m.Key, m.Value)
+		case "length":
+			f.Length, err = schemaInt(m.Key, m.Value)
+		case "align":
+			var s string
+			if s, err = schemaString(m.Key, m.Value); err == nil {
+				switch strings.ToLower(s) {
+				case "left":
+					f.Alignment = text.LeftAligned
+				case "right":
+					f.Alignment = text.RightAligned
+				case "center":
+					f.Alignment = text.Centering
+				default:
+					err = errors.New(fmt.Sprintf("invalid alignment: %q", s))
+				}
+			}
+		case "pad":
+			var s string
+			if s, err = schemaString(m.Key, m.Value); err == nil {
+				if len(s) != 1 {
+					err = errors.New(fmt.Sprintf("pad must be a single-byte character: %q", s))
+				} else {
+					f.PadChar = s[0]
+				}
+			}
+		case "type":
+			var s string
+			if s, err = schemaString(m.Key, m.Value); err == nil {
+				f.Type, err = parseColumnType(s)
+			}
+		case "null":
+			f.NullToken, err = schemaString(m.Key, m.Value)
+		case "format":
+			var s string
+			if s, err = schemaString(m.Key, m.Value); err == nil {
+				f.Format, err = parseFieldFormat(s)
+			}
+		case "scale":
+			f.Scale, err = schemaInt(m.Key, m.Value)
+		case "signed":
+			f.Signed, err = schemaBool(m.Key, m.Value)
+		case "sign_leading":
+			f.SignLeading, err = schemaBool(m.Key, m.Value)
+		case "sign_separate":
+			f.SignSeparate, err = schemaBool(m.Key, m.Value)
+		default:
+			err = errors.New(fmt.Sprintf("unknown key: %q", m.Key))
+		}
+		if err != nil {
+			return f, err
+		}
+	}
+	return f, nil
+}
+
+func schemaString(key string, st json.Structure) (string, error) {
+	s, ok := st.(json.String)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("%s must be a string", key))
+	}
+	return s.Raw(), nil
+}
+
+func schemaBool(key string, st json.Structure) (bool, error) {
+	b, ok := st.(json.Boolean)
+	if !ok {
+		return false, errors.New(fmt.Sprintf("%s must be a boolean", key))
+	}
+	return b.Raw(), nil
+}
+
+func schemaInt(key string, st json.Structure) (int, error) {
+	n, ok := st.(json.Number)
+	if !ok || float64(int(n)) != n.Raw() {
+		return 0, errors.New(fmt.Sprintf("%s must be an integer", key))
+	}
+	return int(n), nil
+}
+
+func parseColumnType(s string) (text.ColumnType, error) {
+	for t, literal := range text.ColumnTypeLiteral {
+		if strings.EqualFold(s, literal) {
+			return t, nil
+		}
+	}
+	return text.UnknownType, errors.New(fmt.Sprintf("invalid type: %q", s))
+}
+
+func parseFieldFormat(s string) (FieldFormat, error) {
+	for f, literal := range FieldFormatLiteral {
+		if strings.EqualFold(s, literal) {
+			return f, nil
+		}
+	}
+	return TextFormat, errors.New(fmt.Sprintf("invalid format: %q", s))
+}
@@ -0,0 +1,451 @@
+package fixedlen
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+var readSchemaTests = []struct {
+	Name   string
+	Input  string
+	Expect *Schema
+	Error  string
+}{
+	{
+		Name: "ReadSchema",
+		Input: `{
+			"unit": "width",
+			"east_asian_encoding": true,
+			"fields": [
+				{"name": "id", "length": 5, "align": "right", "pad": "0", "type": "integer"},
+				{"name": "name", "start": 5, "end": 15, "null": "N/A"},
+				{"name": "active", "end": 16, "length": 1, "type": "Boolean"}
+			]
+		}`,
+		Expect: &Schema{
+			Fields: []SchemaField{
+				{Name: "id", Start: 0, End: 5, Length: 5, Alignment: text.RightAligned, PadChar: '0', Type: text.IntegerType},
+				{Name: "name", Start: 5, End: 15, Length: 10, NullToken: "N/A"},
+				{Name: "active", Start: 15, End: 16, Length: 1, Type: text.BooleanType},
+			},
+			Unit: Unit{PositionUnit: WidthUnit, EastAsianEncoding: true},
+		},
+	},
+	{
+		Name:  "Overlapped Fields",
+		Input: `{"fields": [{"name": "a", "length": 5}, {"name": "b", "start": 4, "length": 3}]}`,
+		Error: "field b overlaps field a",
+	},
+	{
+		Name:  "Gapped Fields",
+		Input: `{"fields": [{"name": "a", "length": 5}, {"name": "b", "start": 6, "length": 3}]}`,
+		Error: "field b: gap from position 5 to 6",
+	},
+	{
+		Name:  "Gap at the Beginning",
+		Input: `{"fields": [{"name": "a", "start": 1, "length": 5}]}`,
+		Error: "field a: gap from position 0 to 1",
+	},
+	{
+		Name:  "Length Mismatch",
+		Input: `{"fields": [{"name": "a", "start": 0, "end": 5, "length": 4}]}`,
+		Error: "field a: length 4 does not match position 0:5",
+	},
+	{
+		Name:  "No Length",
+		Input: `{"fields": [{"name": "a"}]}`,
+		Error: "field a: invalid position 0:0",
+	},
+	{
+		Name:  "Duplicate Field",
+		Input: `{"fields": [{"name": "a", "length": 1}, {"name": "a", "length": 1}]}`,
+		Error: "duplicate field: \"a\"",
+	},
+	{
+		Name:  "Invalid Pad Character",
+		Input: `{"fields": [{"name": "a", "length": 1, "pad": "ab"}]}`,
+		Error: "field 1: pad must be a single-byte character: \"ab\"",
+	},
+	{
+		Name:  "Invalid Type",
+		Input: `{"fields": [{"name": "a", "length": 1, "type": "text"}]}`,
+		Error: "field 1: invalid type: \"text\"",
+	},
+	{
+		Name:  "Unknown Key",
+		Input: `{"fields": [{"name": "a", "size": 1}]}`,
+		Error: "field 1: unknown key: \"size\"",
+	},
+	{
+		Name:  "No Fields",
+		Input: `{"unit": "rune"}`,
+		Error: "schema has no fields",
+	},
+	{
+		Name:  "Invalid JSON",
+		Input: `{"fields": [}`,
+		Error: "line 1, column 13: unexpected token \"}\"",
+	},
+}
+
+func TestReadSchema(t *testing.T) {
+	for _, v := range readSchemaTests {
+		result, err := ReadSchema(strings.NewReader(v.Input))
+		if err != nil {
+			if v.Error == "" {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if v.Error != err.Error() {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(result, v.Expect) {
+			t.Errorf("%s: result = %#v, want %#v", v.Name, result, v.Expect)
+		}
+	}
+}
+
+func TestSchema_ReaderAndWriter(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "id", Length: 4, Alignment: text.RightAligned, PadChar: '0'},
+			{Name: "name", Start: 4, Length: 6, NullToken: "-"},
+			{Name: "note", Start: 10, End: 14, Alignment: text.Centering},
+		},
+		Unit: Unit{PositionUnit: WidthUnit},
+	}
+	records := [][]Field{
+		{NewField("12", text.NotAligned), NewField("日本", text.NotAligned), NewField("ab", text.NotAligned)},
+		{NewField("345", text.LeftAligned), NewNullField(text.RightAligned), NewNullField(text.NotAligned)},
+	}
+	expect := "" +
+		"0012日本   ab \n" +
+		"3450     -    "
+
+	w := new(bytes.Buffer)
+	e, err := NewSchemaWriter(w, schema, text.LF, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	for _, r := range records {
+		if err = e.Write(r); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+	}
+	_ = e.Flush()
+
+	if w.String() != expect {
+		t.Errorf("result = %q, want %q", w.String(), expect)
+	}
+
+	r, err := NewSchemaReader(strings.NewReader(w.String()), schema, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	result, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	output := [][]text.RawText{
+		{text.RawText("12"), text.RawText("日本"), text.RawText("ab")},
+		{text.RawText("3450"), nil, nil},
+	}
+	if !reflect.DeepEqual(result, output) {
+		t.Errorf("records = %q, want %q", result, output)
+	}
+}
+
+func TestSchema_PadCharRoundTrip(t *testing.T) {
+	schema, err := ReadSchema(strings.NewReader(`{
+  "fields": [
+    {"name": "id", "length": 5, "align": "right", "pad": "0"},
+    {"name": "name", "length": 6, "pad": "_"},
+    {"name": "note", "length": 5, "align": "center", "pad": "*"}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	records := [][]Field{
+		{NewField("12", text.NotAligned), NewField("ab", text.NotAligned), NewField("c", text.NotAligned)},
+		{NewField("0", text.NotAligned), NewField("a_b", text.NotAligned), NewField("", text.NotAligned)},
+	}
+	expect := "" +
+		"00012ab____**c**\n" +
+		"00000a_b___*****"
+
+	w := new(bytes.Buffer)
+	e, err := NewSchemaWriter(w, schema, text.LF, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	for _, r := range records {
+		if err = e.Write(r); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+	}
+	_ = e.Flush()
+
+	if w.String() != expect {
+		t.Errorf("result = %q, want %q", w.String(), expect)
+	}
+
+	r, err := NewSchemaReader(strings.NewReader(w.String()), schema, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	result, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	output := [][]text.RawText{
+		{text.RawText("12"), text.RawText("ab"), text.RawText("c")},
+		{text.RawText("0"), text.RawText("a_b"), nil},
+	}
+	if !reflect.DeepEqual(result, output) {
+		t.Errorf("records = %q, want %q", result, output)
+	}
+}
+
+func TestNewSchemaReader_InvalidSchema(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "a", Length: 4},
+			{Name: "b", Start: 3, Length: 2},
+		},
+	}
+	expect := "field b overlaps field a"
+
+	_, err := NewSchemaReader(strings.NewReader(""), schema, text.UTF8)
+	if err == nil {
+		t.Fatalf("no error, want error %q", expect)
+	}
+	if err.Error() != expect {
+		t.Errorf("error = %q, want %q", err.Error(), expect)
+	}
+}
+
+func TestNewSchemaReader_DecimalFormats(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "id", Length: 3, Format: ZonedDecimalFormat},
+			{Name: "amount", Start: 3, Length: 3, Format: PackedDecimalFormat, Scale: 2},
+			{Name: "count", Start: 6, Length: 2, Format: BinaryFormat, Signed: true},
+			{Name: "name", Start: 8, Length: 4, NullToken: "-"},
+		},
+	}
+
+	input := "" +
+		"01A" + "\x12\x34\x5d" + "\xff\xfe" + "ab  " + "\n" +
+		"002" + "   " + "\x00\x0a" + "-   " + "\n"
+
+	r, err := NewSchemaReader(strings.NewReader(input), schema, text.UTF8)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	expect := [][]text.RawText{
+		{text.RawText("11"), text.RawText("-123.45"), text.RawText("-2"), text.RawText("ab")},
+		{text.RawText("2"), nil, text.RawText("10"), nil},
+	}
+	if !reflect.DeepEqual(records, expect) {
+		t.Errorf("records = %q, want %q", records, expect)
+	}
+
+	r, _ = NewSchemaReader(strings.NewReader("0x1"+"\x12\x34\x5d"+"\x00\x01"+"ab  \n123"), schema, text.UTF8)
+	_, err = r.Read()
+	if err == nil || err.Error() != "line 1, column 1: field id: invalid digit in zoned decimal: 0x78" {
+		t.Errorf("error = %v, want data format error of field id", err)
+	}
+
+	r, _ = NewSchemaReader(strings.NewReader("123"), schema, text.UTF8)
+	_, err = r.Read()
+	if err == nil || err.Error() != "line 1, column 4: record is shorter than 12 bytes" {
+		t.Errorf("error = %v, want short record error", err)
+	}
+
+	if _, err = NewSchemaWriter(new(bytes.Buffer), schema, text.LF, text.UTF8); err == nil {
+		t.Errorf("no error, want error for decimal formats")
+	}
+}
+
+var schemaReaderRecordSeparatorTests = []struct {
+	Name            string
+	Input           string
+	Encoding        text.Encoding
+	SingleLine      bool
+	RecordSeparator []byte
+	Output          [][]text.RawText
+	Error           string
+}{
+	{
+		Name:     "Line Feed",
+		Input:    "\x00\x0a" + "ab" + "\n" + "\x00\x0d" + "cd",
+		Encoding: text.UTF8,
+		Output: [][]text.RawText{
+			{text.RawText("10"), text.RawText("ab")},
+			{text.RawText("13"), text.RawText("cd")},
+		},
+	},
+	{
+		Name:       "SingleLine",
+		Input:      "\x00\x0a" + "ab" + "\x00\x0d" + "cd" + "\x00\x0a" + "ef",
+		Encoding:   text.UTF8,
+		SingleLine: true,
+		Output: [][]text.RawText{
+			{text.RawText("10"), text.RawText("ab")},
+			{text.RawText("13"), text.RawText("cd")},
+			{text.RawText("10"), text.RawText("ef")},
+		},
+	},
+	{
+		Name:            "CRLF",
+		Input:           "\x00\x0d" + "ab" + "\r\n" + "\x00\x0a" + "cd" + "\r\n",
+		Encoding:        text.UTF8,
+		RecordSeparator: []byte("\r\n"),
+		Output: [][]text.RawText{
+			{text.RawText("13"), text.RawText("ab")},
+			{text.RawText("10"), text.RawText("cd")},
+		},
+	},
+	{
+		Name:     "CRLF by Default",
+		Input:    "\x00\x0d" + "ab" + "\r\n" + "\x00\x0a" + "cd" + "\r\n",
+		Encoding: text.UTF8,
+		Output: [][]text.RawText{
+			{text.RawText("13"), text.RawText("ab")},
+			{text.RawText("10"), text.RawText("cd")},
+		},
+	},
+	{
+		Name:     "EBCDIC CRLF by Default",
+		Input:    "\x00\x0d" + "\x81\x82" + "\x0d\x25" + "\x00\x25" + "\x83\x84" + "\x0d\x25",
+		Encoding: text.CP037,
+		Output: [][]text.RawText{
+			{text.RawText("13"), text.RawText("ab")},
+			{text.RawText("37"), text.RawText("cd")},
+		},
+	},
+	{
+		Name:     "EBCDIC Line Feed",
+		Input:    "\x00\x25" + "\x81\x82" + "\x25" + "\x00\x15" + "\x83\x84",
+		Encoding: text.CP037,
+		Output: [][]text.RawText{
+			{text.RawText("37"), text.RawText("ab")},
+			{text.RawText("21"), text.RawText("cd")},
+		},
+	},
+	{
+		Name:            "EBCDIC New Line",
+		Input:           "\x00\x15" + "\x81\x82" + "\x15" + "\x00\x25" + "\x83\x84" + "\x15",
+		Encoding:        text.CP037,
+		RecordSeparator: []byte{0x15},
+		Output: [][]text.RawText{
+			{text.RawText("21"), text.RawText("ab")},
+			{text.RawText("37"), text.RawText("cd")},
+		},
+	},
+	{
+		Name:     "Missing Record Separator",
+		Input:    "\x00\x0a" + "ab" + "\x00\x0d" + "cd",
+		Encoding: text.UTF8,
+		Error:    "line 1, column 5: record is not followed by the record separator 0a",
+	},
+}
+
+func TestSchemaReader_RecordSeparator(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "count", Length: 2, Format: BinaryFormat},
+			{Name: "name", Start: 2, Length: 2},
+		},
+	}
+
+	for _, v := range schemaReaderRecordSeparatorTests {
+		r, err := NewSchemaReader(strings.NewReader(v.Input), schema, v.Encoding)
+		if err != nil {
+			t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			continue
+		}
+		r.SingleLine = v.SingleLine
+		if v.RecordSeparator != nil {
+			r.RecordSeparator = v.RecordSeparator
+		}
+
+		records, err := r.ReadAll()
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		if !reflect.DeepEqual(records, v.Output) {
+			t.Errorf("%s: records = %q, want %q", v.Name, records, v.Output)
+		}
+	}
+}
+
+func TestSchemaWriter_Type(t *testing.T) {
+	schema := &Schema{
+		Fields: []SchemaField{
+			{Name: "id", Length: 3, Type: text.IntegerType},
+			{Name: "rate", Start: 3, Length: 5, Type: text.FloatType},
+			{Name: "flag", Start: 8, Length: 5, Type: text.BooleanType},
+			{Name: "note", Start: 13, Length: 3, Type: text.StringType},
+		},
+	}
+
+	w := new(bytes.Buffer)
+	e, _ := NewSchemaWriter(w, schema, text.LF, text.UTF8)
+
+	if err := e.Write([]Field{NewField("12", text.NotAligned), NewField("-1.5", text.NotAligned), NewField("true", text.NotAligned), NewField("ab", text.NotAligned)}); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if err := e.Write([]Field{NewNullField(text.NotAligned), NewField("", text.NotAligned)}); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	errorTests := []struct {
+		Record []Field
+		Error  string
+	}{
+		{Record: []Field{NewField("1.5", text.NotAligned)}, Error: "field id: invalid INTEGER value: \"1.5\""},
+		{Record: []Field{NewField("1", text.NotAligned), NewField("1e3", text.NotAligned)}, Error: "field rate: invalid FLOAT value: \"1e3\""},
+		{Record: []Field{NewField("1", text.NotAligned), NewField("1", text.NotAligned), NewField("yes", text.NotAligned)}, Error: "field flag: invalid BOOLEAN value: \"yes\""},
+	}
+	for _, v := range errorTests {
+		err := e.Write(v.Record)
+		if err == nil || err.Error() != v.Error {
+			t.Errorf("error = %v, want error %q", err, v.Error)
+		}
+	}
+	_ = e.Flush()
+
+	expect := "" +
+		"12 -1.5 true ab \n" +
+		"                "
+	if w.String() != expect {
+		t.Errorf("result = %q, want %q", w.String(), expect)
+	}
+}
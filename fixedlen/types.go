@@ -46,6 +46,9 @@ func (p DelimiterPositions) String() string {
 type Field struct {
 	Contents  string
 	Alignment text.FieldAlignment
+
+	// Null makes Writer write NullToken instead of Contents.
+	Null bool
 }
 
 func NewField(contents string, alignment text.FieldAlignment) Field {
@@ -54,3 +57,10 @@ func NewField(contents string, alignment text.FieldAlignment) Field {
 		Alignment: alignment,
 	}
 }
+
+func NewNullField(alignment text.FieldAlignment) Field {
+	return Field{
+		Alignment: alignment,
+		Null:      true,
+	}
+}
@@ -0,0 +1,80 @@
+package fixedlen
+
+import (
+	"unicode/utf8"
+
+	"github.com/mithrandie/go-text"
+)
+
+type PositionUnit int
+
+const (
+	ByteUnit PositionUnit = iota
+	RuneUnit
+	WidthUnit
+)
+
+var PositionUnitLiteral = map[PositionUnit]string{
+	ByteUnit:  "byte",
+	RuneUnit:  "character",
+	WidthUnit: "column",
+}
+
+func (u PositionUnit) String() string {
+	return PositionUnitLiteral[u]
+}
+
+// Unit determines how delimiter positions are measured.
+// The zero value measures positions in encoded bytes.
+type Unit struct {
+	PositionUnit PositionUnit
+
+	// Options of text.Width used in WidthUnit.
+	EastAsianEncoding    bool
+	CountDiacriticalSign bool
+	CountFormatCode      bool
+}
+
+func (u Unit) RuneSize(r rune, enc text.Encoding) int {
+	switch u.PositionUnit {
+	case RuneUnit:
+		return 1
+	case WidthUnit:
+		return text.RuneWidth(r, u.EastAsianEncoding, u.CountDiacriticalSign, u.CountFormatCode)
+	}
+	return text.RuneByteSize(r, enc)
+}
+
+func (u Unit) Size(s string, enc text.Encoding) int {
+	switch u.PositionUnit {
+	case RuneUnit:
+		return utf8.RuneCountInString(s)
+	case WidthUnit:
+		return text.Width(s, u.EastAsianEncoding, u.CountDiacriticalSign, u.CountFormatCode)
+	}
+	return text.ByteSize(s, enc)
+}
+
+// truncate returns the longest prefix of s within size.
+func (u Unit) truncate(s string, size int, enc text.Encoding) string {
+	countShiftCodes := u.PositionUnit == ByteUnit && text.IsShiftEncoding(enc)
+
+	pos := 0
+	shifted := false
+	for i, c := range s {
+		n := u.RuneSize(c, enc)
+		if countShiftCodes {
+			if n < 2 {
+				shifted = false
+			} else if !shifted {
+				n = n + 2
+				shifted = true
+			}
+		}
+		if size < pos+n {
+			return s[:i]
+		}
+		pos = pos + n
+	}
+	return s
+}
@@ -3,46 +3,157 @@ package fixedlen
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/mithrandie/go-text"
 )
 
+type OverflowPolicy int
+
+const (
+	OverflowError OverflowPolicy = iota
+	OverflowTruncate
+	OverflowTruncateWithMarker
+	OverflowReport
+)
+
+var OverflowPolicyLiteral = map[OverflowPolicy]string{
+	OverflowError:              "ERROR",
+	OverflowTruncate:           "TRUNCATE",
+	OverflowTruncateWithMarker: "TRUNCATE_WITH_MARKER",
+	OverflowReport:             "REPORT",
+}
+
+func (p OverflowPolicy) String() string {
+	return OverflowPolicyLiteral[p]
+}
+
+// OverflowField is a value truncated by OverflowReport.
+type OverflowField struct {
+	// Record is the zero-based number of the record counting every record written.
+	Record int
+	// Field is the zero-based index of the field in the record.
+	Field int
+	Value string
+}
+
 type Writer struct {
 	InsertSpace bool
 	PadChar     byte
 	SingleLine  bool
 
+	// NullToken is written for NULL fields with the alignment of the field.
+	NullToken string
+
+	// Unit is the unit of the delimiter positions.
+	Unit Unit
+
+	// OverflowPolicy determines how values longer than the fields are written.
+	// Values are truncated at character boundaries, and numbers are filled with "*" instead of being truncated.
+	// OverflowReport truncates values and appends them to Overflows.
+	OverflowPolicy OverflowPolicy
+	// OverflowMarker replaces the end of values truncated by OverflowTruncateWithMarker.
+	OverflowMarker string
+	Overflows      []OverflowField
+
+	// ZeroPadNumbers pads right-aligned numbers with zeros following the sign instead of the pad character.
+	ZeroPadNumbers bool
+
+	// Framing determines how records are separated. Records framed by RDWFraming and LengthPrefixFraming
+	// are not followed by line breaks.
+	Framing Framing
+	// LengthPrefixSize is the number of digits of the length in LengthPrefixFraming.
+	LengthPrefixSize int
+
+	schema             *Schema
 	delimiterPositions DelimiterPositions
 	encoding           text.Encoding
 	writer             *bufio.Writer
+	fieldEncoder       *fieldEncoder
+	frameWriter        *frameWriter
 	lineBreak          string
 	appended           bool
+	record             int
 }
 
+// NewWriter returns a writer encoding fields in enc.
+//
+// In CP930 and CP939, every field is encoded separately so that double-byte characters in a field
+// are enclosed in their own shift codes.
 func NewWriter(w io.Writer, delimiterPositions DelimiterPositions, lineBreak text.LineBreak, enc text.Encoding) (*Writer, error) {
-	writer, err := text.GetTransformWriter(w, enc)
-	if err != nil {
-		return nil, err
+	_, bom, _ := text.SeekEncoding(enc)
+	fw := &frameWriter{writer: w, bom: bom}
+
+	var fe *fieldEncoder
+	var writer io.Writer
+	if text.IsShiftEncoding(enc) {
+		fe = &fieldEncoder{writer: fw, encoding: enc}
+		writer = fe
+	} else {
+		var err error
+		if writer, err = text.GetTransformWriter(fw, enc); err != nil {
+			return nil, err
+		}
 	}
 
 	return &Writer{
 		InsertSpace:        false,
 		PadChar:            ' ',
+		OverflowMarker:     "~",
+		LengthPrefixSize:   4,
 		delimiterPositions: delimiterPositions,
 		encoding:           enc,
 		lineBreak:          lineBreak.Value(),
 		writer:             bufio.NewWriter(writer),
+		fieldEncoder:       fe,
+		frameWriter:        fw,
 	}, nil
 }
 
+// NewSchemaWriter returns a writer that aligns fields by the schema.
+func NewSchemaWriter(w io.Writer, schema *Schema, lineBreak text.LineBreak, enc text.Encoding) (*Writer, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	if schema.HasDecimalFormats() {
+		return nil, errors.New("writing fields other than text format is not supported")
+	}
+
+	writer, err := NewWriter(w, schema.Positions(), lineBreak, enc)
+	if err != nil {
+		return nil, err
+	}
+	writer.Unit = schema.Unit
+	writer.schema = schema
+	return writer, nil
+}
+
 func (e *Writer) Write(record []Field) error {
-	if !e.SingleLine && e.appended {
+	e.frameWriter.framing = e.Framing
+
+	if e.schema != nil {
+		for i := 0; i < len(record) && i < len(e.schema.Fields); i++ {
+			if record[i].Null || len(record[i].Contents) < 1 {
+				continue
+			}
+			if err := e.schema.Fields[i].checkType(record[i].Contents); err != nil {
+				return err
+			}
+		}
+	}
+
+	if e.Framing == LineFraming && !e.SingleLine && e.appended {
 		if _, err := e.writer.WriteString(e.lineBreak); err != nil {
 			return err
 		}
+		if err := e.endField(); err != nil {
+			return err
+		}
 	} else {
 		e.appended = true
 	}
@@ -60,60 +171,255 @@ func (e *Writer) Write(record []Field) error {
 		}
 
 		size := end - start
+		padChar := e.PadChar
+		nullToken := e.NullToken
+		if e.schema != nil && i < len(e.schema.Fields) {
+			sf := e.schema.Fields[i]
+			if sf.PadChar != 0 {
+				padChar = sf.PadChar
+			}
+			if 0 < len(sf.NullToken) {
+				nullToken = sf.NullToken
+			}
+		}
+
 		if i < len(record) {
-			if err := e.addField(record[i], size); err != nil {
+			field := record[i]
+			if field.Alignment == text.NotAligned && e.schema != nil && i < len(e.schema.Fields) {
+				field.Alignment = e.schema.Fields[i].Alignment
+			}
+			if field.Null {
+				field.Contents = nullToken
+			}
+			if err := e.addField(field, i, size, padChar); err != nil {
 				return err
 			}
 		} else {
-			if _, err := e.writer.Write(bytes.Repeat([]byte{e.PadChar}, size)); err != nil {
+			if _, err := e.writer.Write(e.padding(padChar, size)); err != nil {
 				return err
 			}
 		}
+		if err := e.endField(); err != nil {
+			return err
+		}
 		start = end
 	}
 
+	if e.Framing != LineFraming {
+		if err := e.Flush(); err != nil {
+			return err
+		}
+		if err := e.frameWriter.writeFrame(e.LengthPrefixSize); err != nil {
+			return err
+		}
+	}
+
+	e.record++
 	return nil
 }
 
-func (e *Writer) addField(field Field, fieldSize int) error {
-	size := text.ByteSize(field.Contents, e.encoding)
+func (e *Writer) addField(field Field, index int, fieldSize int, padChar byte) error {
+	size := e.Unit.Size(field.Contents, e.encoding)
 	if fieldSize < size {
-		return errors.New(fmt.Sprintf("value is too long: %q for %d byte(s) length field", field.Contents, fieldSize))
+		if e.OverflowPolicy == OverflowError {
+			return errors.New(fmt.Sprintf("value is too long: %q for %d %s(s) length field", field.Contents, fieldSize, e.Unit.PositionUnit))
+		}
+
+		if e.OverflowPolicy == OverflowReport {
+			e.Overflows = append(e.Overflows, OverflowField{
+				Record: e.record,
+				Field:  index,
+				Value:  field.Contents,
+			})
+		}
+
+		switch {
+		case isNumber(field.Contents):
+			field.Contents = strings.Repeat("*", fieldSize/e.Unit.RuneSize('*', e.encoding))
+		case e.OverflowPolicy == OverflowTruncateWithMarker && e.Unit.Size(e.OverflowMarker, e.encoding) <= fieldSize:
+			field.Contents = e.Unit.truncate(field.Contents, fieldSize-e.Unit.Size(e.OverflowMarker, e.encoding), e.encoding) + e.OverflowMarker
+		default:
+			field.Contents = e.Unit.truncate(field.Contents, fieldSize, e.encoding)
+		}
+		size = e.Unit.Size(field.Contents, e.encoding)
 	}
 
 	padLen := fieldSize - size
+	if padLen < 0 {
+		padLen = 0
+	}
 
 	switch field.Alignment {
 	case text.Centering:
-		halfPadLen := padLen / 2
-		if _, err := e.writer.Write(bytes.Repeat([]byte{e.PadChar}, halfPadLen)); err != nil {
+		pad := e.padding(padChar, padLen)
+		halfPadLen := len(pad) / 2
+		if _, err := e.writer.Write(pad[:halfPadLen]); err != nil {
 			return err
 		}
 		if _, err := e.writer.WriteString(field.Contents); err != nil {
 			return err
 		}
-		if _, err := e.writer.Write(bytes.Repeat([]byte{e.PadChar}, padLen-halfPadLen)); err != nil {
+		if _, err := e.writer.Write(pad[halfPadLen:]); err != nil {
 			return err
 		}
 	case text.RightAligned:
-		if _, err := e.writer.Write(bytes.Repeat([]byte{e.PadChar}, padLen)); err != nil {
+		contents := field.Contents
+		if e.ZeroPadNumbers && isNumber(contents) {
+			if contents[0] == '-' || contents[0] == '+' {
+				if err := e.writer.WriteByte(contents[0]); err != nil {
+					return err
+				}
+				contents = contents[1:]
+			}
+			padChar = '0'
+		}
+		if _, err := e.writer.Write(e.padding(padChar, padLen)); err != nil {
 			return err
 		}
-		if _, err := e.writer.WriteString(field.Contents); err != nil {
+		if _, err := e.writer.WriteString(contents); err != nil {
 			return err
 		}
 	default:
 		if _, err := e.writer.WriteString(field.Contents); err != nil {
 			return err
 		}
-		if _, err := e.writer.Write(bytes.Repeat([]byte{e.PadChar}, padLen)); err != nil {
-
+		if _, err := e.writer.Write(e.padding(padChar, padLen)); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// padding returns pad characters filling size in the unit.
+// In ByteUnit, the number of characters is the size divided by the encoded size of the pad character.
+func (e *Writer) padding(padChar byte, size int) []byte {
+	if size < 1 {
+		return nil
+	}
+	if n := e.Unit.RuneSize(rune(padChar), e.encoding); 1 < n {
+		size = size / n
+	}
+	return bytes.Repeat([]byte{padChar}, size)
+}
+
+// isNumber returns true if s is a decimal number with an optional sign.
+func isNumber(s string) bool {
+	if 0 < len(s) && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+
+	digits := 0
+	point := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case '0' <= s[i] && s[i] <= '9':
+			digits++
+		case s[i] == '.' && !point:
+			point = true
+		default:
+			return false
+		}
+	}
+	return 0 < digits
+}
+
+func (e *Writer) endField() error {
+	if e.fieldEncoder == nil {
+		return nil
+	}
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	return e.fieldEncoder.Flush()
+}
+
 func (e *Writer) Flush() error {
-	return e.writer.Flush()
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	if e.fieldEncoder != nil {
+		return e.fieldEncoder.Flush()
+	}
+	return nil
+}
+
+// fieldEncoder buffers a field and encodes it at once.
+type fieldEncoder struct {
+	writer   io.Writer
+	encoding text.Encoding
+	buf      []byte
+}
+
+func (fe *fieldEncoder) Write(p []byte) (int, error) {
+	fe.buf = append(fe.buf, p...)
+	return len(p), nil
+}
+
+func (fe *fieldEncoder) Flush() error {
+	if len(fe.buf) < 1 {
+		return nil
+	}
+	b, err := text.Encode(fe.buf, fe.encoding)
+	fe.buf = fe.buf[:0]
+	if err != nil {
+		return err
+	}
+	_, err = fe.writer.Write(b)
+	return err
+}
+
+// frameWriter buffers an encoded record to precede it with a Record Descriptor Word or a length prefix.
+// A byte order mark is written before the first frame.
+type frameWriter struct {
+	writer  io.Writer
+	framing Framing
+	bom     int
+	buf     []byte
+}
+
+func (fw *frameWriter) Write(p []byte) (int, error) {
+	if fw.framing == LineFraming {
+		fw.bom = 0
+		return fw.writer.Write(p)
+	}
+	fw.buf = append(fw.buf, p...)
+	return len(p), nil
+}
+
+func (fw *frameWriter) writeFrame(lengthPrefixSize int) error {
+	b := fw.buf
+	fw.buf = fw.buf[:0]
+
+	if 0 < fw.bom && fw.bom <= len(b) {
+		if _, err := fw.writer.Write(b[:fw.bom]); err != nil {
+			return err
+		}
+		b = b[fw.bom:]
+		fw.bom = 0
+	}
+
+	var prefix []byte
+	switch fw.framing {
+	case RDWFraming:
+		if 0xffff < len(b)+4 {
+			return errors.New(fmt.Sprintf("record of %d bytes is too long for a record descriptor word", len(b)))
+		}
+		prefix = make([]byte, 4)
+		binary.BigEndian.PutUint16(prefix, uint16(len(b)+4))
+	case LengthPrefixFraming:
+		length := strconv.Itoa(len(b))
+		if lengthPrefixSize < len(length) {
+			return errors.New(fmt.Sprintf("record of %d bytes is too long for a length prefix of %d digits", len(b), lengthPrefixSize))
+		}
+		prefix = []byte(strings.Repeat("0", lengthPrefixSize-len(length)) + length)
+	default:
+		return errors.New(fmt.Sprintf("invalid framing: %d", fw.framing))
+	}
+
+	if _, err := fw.writer.Write(prefix); err != nil {
+		return err
+	}
+	_, err := fw.writer.Write(b)
+	return err
 }
@@ -2,6 +2,7 @@ package fixedlen
 
 import (
 	"bytes"
+	"reflect"
 	"testing"
 
 	"github.com/mithrandie/go-text"
@@ -15,6 +16,7 @@ var writerWriteTests = []struct {
 	Encoding           text.Encoding
 	InsertSpace        bool
 	SingleLine         bool
+	Unit               Unit
 	Expect             string
 	Error              string
 }{
@@ -108,6 +110,38 @@ var writerWriteTests = []struct {
 			"abc  " + string([]byte{0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea}) + "    def  \n" +
 			"ghi  jkl       mno  ",
 	},
+	{
+		Name: "Fixed-Length Encode to CP930",
+		Records: [][]Field{
+			{
+				{Contents: "日本", Alignment: text.LeftAligned},
+				{Contents: "語", Alignment: text.LeftAligned},
+			},
+			{
+				{Contents: "A", Alignment: text.LeftAligned},
+				{Contents: "語", Alignment: text.RightAligned},
+			},
+		},
+		DelimiterPositions: []int{6, 10},
+		LineBreak:          text.LF,
+		Encoding:           text.CP930,
+		Expect: "" +
+			"\x0e\x45\x62\x45\x66\x0f\x0e\x48\xe7\x0f\x25" +
+			"\xc1\x40\x40\x40\x40\x40\x0e\x48\xe7\x0f",
+	},
+	{
+		Name: "Fixed-Length Encode to CP930 with too long value",
+		Records: [][]Field{
+			{
+				{Contents: "日本", Alignment: text.LeftAligned},
+				{Contents: "語", Alignment: text.LeftAligned},
+			},
+		},
+		DelimiterPositions: []int{5, 10},
+		LineBreak:          text.LF,
+		Encoding:           text.CP930,
+		Error:              "value is too long: \"日本\" for 5 byte(s) length field",
+	},
 	{
 		Name: "Encode to UTF8M",
 		Records: [][]Field{
@@ -272,6 +306,53 @@ var writerWriteTests = []struct {
 			"abcabcdefdef" +
 			"ghi   jklmno",
 	},
+	{
+		Name: "Display Width",
+		Records: [][]Field{
+			{
+				{Contents: "日本", Alignment: text.LeftAligned},
+				{Contents: "abc", Alignment: text.RightAligned},
+			},
+			{
+				{Contents: "a", Alignment: text.LeftAligned},
+				{Contents: "語", Alignment: text.Centering},
+			},
+		},
+		DelimiterPositions: []int{5, 10},
+		LineBreak:          text.LF,
+		Encoding:           text.UTF8,
+		Unit:               Unit{PositionUnit: WidthUnit},
+		Expect: "" +
+			"日本   abc\n" +
+			"a     語  ",
+	},
+	{
+		Name: "Runes",
+		Records: [][]Field{
+			{
+				{Contents: "日本", Alignment: text.LeftAligned},
+				{Contents: "abc", Alignment: text.RightAligned},
+			},
+		},
+		DelimiterPositions: []int{3, 6},
+		LineBreak:          text.LF,
+		Encoding:           text.SJIS,
+		Unit:               Unit{PositionUnit: RuneUnit},
+		Expect:             string([]byte{0x93, 0xfa, 0x96, 0x7b}) + " abc",
+	},
+	{
+		Name: "Display Width Overflow",
+		Records: [][]Field{
+			{
+				{Contents: "日本語", Alignment: text.LeftAligned},
+			},
+		},
+		DelimiterPositions: []int{5},
+		LineBreak:          text.LF,
+		Encoding:           text.UTF8,
+		Unit:               Unit{PositionUnit: WidthUnit},
+		Error:              "value is too long: \"日本語\" for 5 column(s) length field",
+	},
 }
 
 func TestWriter_Write(t *testing.T) {
@@ -283,6 +364,7 @@ func TestWriter_Write(t *testing.T) {
 		e, _ := NewWriter(w, v.DelimiterPositions, v.LineBreak, v.Encoding)
 		e.InsertSpace = v.InsertSpace
 		e.SingleLine = v.SingleLine
+		e.Unit = v.Unit
 
 		for _, r := range v.Records {
 			err := e.Write(r)
@@ -314,3 +396,274 @@ func TestWriter_Write(t *testing.T) {
 		}
 	}
 }
+
+func TestWriter_WriteWithNullToken(t *testing.T) {
+	record := []Field{NewNullField(text.RightAligned), NewField("abc", text.LeftAligned), NewNullField(text.LeftAligned)}
+	expect := "   NAabc  NA   "
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, []int{5, 10, 15}, text.LF, text.UTF8)
+	w.NullToken = "NA"
+	if err := w.Write(record); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	_ = w.Flush()
+
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+}
+
+var writerWriteOverflowTests = []struct {
+	Name           string
+	Record         []Field
+	Positions      []int
+	Encoding       text.Encoding
+	OverflowPolicy OverflowPolicy
+	OverflowMarker string
+	ZeroPadNumbers bool
+	Expect         string
+	Overflows      []OverflowField
+	Error          string
+}{
+	{
+		Name:   "Error",
+		Record: []Field{NewField("abcdefg", text.LeftAligned), NewField("12", text.RightAligned)},
+		Error:  "value is too long: \"abcdefg\" for 5 byte(s) length field",
+	},
+	{
+		Name:           "Truncate UTF-8",
+		Record:         []Field{NewField("ab日本語", text.LeftAligned), NewField("12", text.RightAligned)},
+		OverflowPolicy: OverflowTruncate,
+		Expect:         "ab日   12",
+	},
+	{
+		Name:           "Truncate SJIS",
+		Record:         []Field{NewField("abc日本", text.LeftAligned), NewField("12", text.RightAligned)},
+		Encoding:       text.SJIS,
+		OverflowPolicy: OverflowTruncate,
+		Expect:         "abc" + string([]byte{0x93, 0xfa}) + "   12",
+	},
+	{
+		Name:           "Truncate CP930",
+		Record:         []Field{NewField("日本語", text.LeftAligned), NewField("12", text.RightAligned)},
+		Encoding:       text.CP930,
+		OverflowPolicy: OverflowTruncate,
+		Expect:         "\x0e\x45\x62\x0f\x40\x40\x40\x40\xf1\xf2",
+	},
+	{
+		Name:           "Numbers in UTF-16",
+		Record:         []Field{NewField("12345", text.RightAligned), NewField("ab", text.Centering)},
+		Positions:      []int{4, 12},
+		Encoding:       text.UTF16LE,
+		OverflowPolicy: OverflowTruncate,
+		Expect:         "*\x00*\x00 \x00a\x00b\x00 \x00",
+	},
+	{
+		Name:           "Zero Padding in UTF-16",
+		Record:         []Field{NewField("-1", text.RightAligned), NewField("ab", text.LeftAligned)},
+		Positions:      []int{8, 12},
+		Encoding:       text.UTF16BE,
+		ZeroPadNumbers: true,
+		Expect:         "\x00-\x000\x000\x001\x00a\x00b",
+	},
+	{
+		Name:           "Truncate with Marker",
+		Record:         []Field{NewField("abcdefg", text.LeftAligned), NewField("12", text.RightAligned)},
+		OverflowPolicy: OverflowTruncateWithMarker,
+		Expect:         "abcd~   12",
+	},
+	{
+		Name:           "Truncate with Multibyte Marker",
+		Record:         []Field{NewField("abcdefg", text.LeftAligned), NewField("12", text.RightAligned)},
+		OverflowPolicy: OverflowTruncateWithMarker,
+		OverflowMarker: "…",
+		Expect:         "ab…   12",
+	},
+	{
+		Name:           "Numbers are Not Truncated",
+		Record:         []Field{NewField("ab", text.LeftAligned), NewField("-12345", text.RightAligned)},
+		OverflowPolicy: OverflowTruncate,
+		Expect:         "ab   *****",
+	},
+	{
+		Name:           "Report",
+		Record:         []Field{NewField("abcdefg", text.LeftAligned), NewField("123456", text.RightAligned)},
+		OverflowPolicy: OverflowReport,
+		Expect:         "abcde*****",
+		Overflows: []OverflowField{
+			{Record: 0, Field: 0, Value: "abcdefg"},
+			{Record: 0, Field: 1, Value: "123456"},
+		},
+	},
+	{
+		Name:           "Zero Padding",
+		Record:         []Field{NewField("-1.5", text.RightAligned), NewField("12", text.RightAligned)},
+		ZeroPadNumbers: true,
+		Expect:         "-01.500012",
+	},
+	{
+		Name:           "Zero Padding Not Applied to Text",
+		Record:         []Field{NewField("1-2", text.RightAligned), NewField("12", text.LeftAligned)},
+		ZeroPadNumbers: true,
+		Expect:         "  1-212   ",
+	},
+}
+
+func TestWriter_WriteOverflow(t *testing.T) {
+	for _, v := range writerWriteOverflowTests {
+		enc := v.Encoding
+		if enc == text.AUTO {
+			enc = text.UTF8
+		}
+
+		positions := v.Positions
+		if positions == nil {
+			positions = []int{5, 10}
+		}
+
+		buf := new(bytes.Buffer)
+		w, _ := NewWriter(buf, positions, text.LF, enc)
+		w.OverflowPolicy = v.OverflowPolicy
+		if 0 < len(v.OverflowMarker) {
+			w.OverflowMarker = v.OverflowMarker
+		}
+		w.ZeroPadNumbers = v.ZeroPadNumbers
+
+		err := w.Write(v.Record)
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err, v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		_ = w.Flush()
+
+		if buf.String() != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, buf.String(), v.Expect)
+		}
+		if !reflect.DeepEqual(w.Overflows, v.Overflows) {
+			t.Errorf("%s: overflows = %v, want %v", v.Name, w.Overflows, v.Overflows)
+		}
+	}
+}
+
+var writerWriteFramedTests = []struct {
+	Name             string
+	Positions        []int
+	Framing          Framing
+	LengthPrefixSize int
+	Encoding         text.Encoding
+	Records          [][]Field
+	Expect           string
+	Error            string
+}{
+	{
+		Name:    "RDW",
+		Framing: RDWFraming,
+		Records: [][]Field{
+			{NewField("abc", text.LeftAligned), NewField("def", text.RightAligned)},
+			{NewField("gh", text.LeftAligned)},
+		},
+		Expect: "\x00\x0c\x00\x00abc  def" + "\x00\x0c\x00\x00gh      ",
+	},
+	{
+		Name:     "RDW UTF-8 with BOM",
+		Framing:  RDWFraming,
+		Encoding: text.UTF8M,
+		Records: [][]Field{
+			{NewField("abc", text.LeftAligned), NewField("def", text.LeftAligned)},
+		},
+		Expect: text.UTF8BOM + "\x00\x0c\x00\x00abc  def",
+	},
+	{
+		Name:     "RDW CP930",
+		Framing:  RDWFraming,
+		Encoding: text.CP930,
+		Records: [][]Field{
+			{NewField("一", text.LeftAligned), NewField("BC", text.LeftAligned)},
+		},
+		Expect: "\x00\x0c\x00\x00\x0e\x45\x41\x0f\x40\xc2\xc3\x40",
+	},
+	{
+		Name:             "Length Prefix",
+		Framing:          LengthPrefixFraming,
+		LengthPrefixSize: 2,
+		Records: [][]Field{
+			{NewField("abc", text.LeftAligned), NewField("def", text.RightAligned)},
+			{NewField("gh", text.LeftAligned)},
+		},
+		Expect: "08abc  def" + "08gh      ",
+	},
+	{
+		Name:             "Length Prefix Overflow",
+		Positions:        []int{5, 10},
+		Framing:          LengthPrefixFraming,
+		LengthPrefixSize: 1,
+		Records: [][]Field{
+			{NewField("abc", text.LeftAligned), NewField("def", text.RightAligned)},
+		},
+		Error: "record of 10 bytes is too long for a length prefix of 1 digits",
+	},
+}
+
+func TestWriter_WriteFramed(t *testing.T) {
+	for _, v := range writerWriteFramedTests {
+		enc := v.Encoding
+		if enc == text.AUTO {
+			enc = text.UTF8
+		}
+
+		positions := v.Positions
+		if positions == nil {
+			positions = []int{5, 8}
+		}
+
+		buf := new(bytes.Buffer)
+		w, _ := NewWriter(buf, positions, text.LF, enc)
+		w.Framing = v.Framing
+		if 0 < v.LengthPrefixSize {
+			w.LengthPrefixSize = v.LengthPrefixSize
+		}
+
+		var err error
+		for _, record := range v.Records {
+			if err = w.Write(record); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if err.Error() != v.Error {
+				t.Errorf("%s: error %q, want error %q", v.Name, err, v.Error)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+			continue
+		}
+		_ = w.Flush()
+
+		if buf.String() != v.Expect {
+			t.Errorf("%s: result = %q, want %q", v.Name, buf.String(), v.Expect)
+		}
+
+		r, _ := NewReader(bytes.NewReader(buf.Bytes()), positions, enc)
+		r.Framing = v.Framing
+		r.LengthPrefixSize = w.LengthPrefixSize
+		records, err := r.ReadAll()
+		if err != nil {
+			t.Errorf("%s: unexpected error %q in reading", v.Name, err.Error())
+		} else if len(records) != len(v.Records) {
+			t.Errorf("%s: %d records are read, want %d", v.Name, len(records), len(v.Records))
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package text
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RecordIndexSignature is the leading bytes of a persisted record index.
+const RecordIndexSignature = "GTRI"
+
+const recordIndexVersion = 1
+
+var ErrInvalidRecordIndex = errors.New("invalid record index")
+
+type IndexEntry struct {
+	// Record is the zero-based number of the record counting every record read including the header.
+	Record int
+	// Offset is the byte offset of the record in the source.
+	Offset int64
+	// Line is the line number where the record starts.
+	Line int
+}
+
+// RecordIndex holds the position of every Interval-th record.
+type RecordIndex struct {
+	Interval int
+	Entries  []IndexEntry
+}
+
+func NewRecordIndex(interval int) *RecordIndex {
+	if interval < 1 {
+		interval = 1
+	}
+	return &RecordIndex{
+		Interval: interval,
+		Entries:  make([]IndexEntry, 0, 64),
+	}
+}
+
+// Add appends the position of the record if the record is a multiple of Interval
+// and is beyond the last entry.
+func (idx *RecordIndex) Add(record int, offset int64, line int) {
+	if record%idx.Interval != 0 {
+		return
+	}
+	if 0 < len(idx.Entries) && record <= idx.Entries[len(idx.Entries)-1].Record {
+		return
+	}
+	idx.Entries = append(idx.Entries, IndexEntry{
+		Record: record,
+		Offset: offset,
+		Line:   line,
+	})
+}
+
+// Lookup returns the nearest entry at or before the record.
+func (idx *RecordIndex) Lookup(record int) (IndexEntry, bool) {
+	lo, hi := 0, len(idx.Entries)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if idx.Entries[mid].Record <= record {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	if lo < 1 {
+		return IndexEntry{}, false
+	}
+	return idx.Entries[lo-1], true
+}
+
+// WriteTo writes the index in the following format.
+//
+//	signature "GTRI", version byte, interval, number of entries,
+//	and for each entry the differences of record, offset and line from the previous entry.
+//
+// All numbers are unsigned varints.
+func (idx *RecordIndex) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	buf := make([]byte, binary.MaxVarintLen64)
+
+	write := func(p []byte) error {
+		c, err := bw.Write(p)
+		n = n + int64(c)
+		return err
+	}
+	writeUvarint := func(v uint64) error {
+		return write(buf[:binary.PutUvarint(buf, v)])
+	}
+
+	if err := write([]byte(RecordIndexSignature)); err != nil {
+		return n, err
+	}
+	if err := write([]byte{recordIndexVersion}); err != nil {
+		return n, err
+	}
+	if err := writeUvarint(uint64(idx.Interval)); err != nil {
+		return n, err
+	}
+	if err := writeUvarint(uint64(len(idx.Entries))); err != nil {
+		return n, err
+	}
+
+	prev := IndexEntry{}
+	for _, e := range idx.Entries {
+		if e.Record < prev.Record || e.Offset < prev.Offset || e.Line < prev.Line {
+			return n, errors.New(fmt.Sprintf("record index entries are not in order at record %d", e.Record))
+		}
+		if err := writeUvarint(uint64(e.Record - prev.Record)); err != nil {
+			return n, err
+		}
+		if err := writeUvarint(uint64(e.Offset - prev.Offset)); err != nil {
+			return n, err
+		}
+		if err := writeUvarint(uint64(e.Line - prev.Line)); err != nil {
+			return n, err
+		}
+		prev = e
+	}
+
+	return n, bw.Flush()
+}
+
+// ReadRecordIndex reads an index written by RecordIndex.WriteTo.
+func ReadRecordIndex(r io.Reader) (*RecordIndex, error) {
+	br := bufio.NewReader(r)
+
+	head := make([]byte, len(RecordIndexSignature)+1)
+	if _, err := io.ReadFull(br, head); err != nil {
+		return nil, ErrInvalidRecordIndex
+	}
+	if string(head[:len(RecordIndexSignature)]) != RecordIndexSignature {
+		return nil, ErrInvalidRecordIndex
+	}
+	if head[len(RecordIndexSignature)] != recordIndexVersion {
+		return nil, errors.New(fmt.Sprintf("unsupported record index version %d", head[len(RecordIndexSignature)]))
+	}
+
+	readInt := func() (int, error) {
+		v, err := binary.ReadUvarint(br)
+		if err != nil || int64(v) < 0 || uint64(int(v)) != v {
+			return 0, ErrInvalidRecordIndex
+		}
+		return int(v), nil
+	}
+
+	interval, err := readInt()
+	if err != nil || interval < 1 {
+		return nil, ErrInvalidRecordIndex
+	}
+	count, err := readInt()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &RecordIndex{
+		Interval: interval,
+		Entries:  make([]IndexEntry, 0, minInt(count, 1024)),
+	}
+	prev := IndexEntry{}
+	for i := 0; i < count; i++ {
+		var record, offset, line int
+		if record, err = readInt(); err != nil {
+			return nil, err
+		}
+		if offset, err = readInt(); err != nil {
+			return nil, err
+		}
+		if line, err = readInt(); err != nil {
+			return nil, err
+		}
+		prev = IndexEntry{
+			Record: prev.Record + record,
+			Offset: prev.Offset + int64(offset),
+			Line:   prev.Line + line,
+		}
+		idx.Entries = append(idx.Entries, prev)
+	}
+	return idx, nil
+}
+
+// SeekEncoding returns the encoding to decode a source from the middle,
+// and the size of the byte order mark at the beginning of the source.
+// Sources in CP930 and CP939 must be decoded from a position in the single-byte mode.
+func SeekEncoding(enc Encoding) (Encoding, int, error) {
+	switch enc {
+	case UTF8, SJIS, UTF16BE, UTF16LE, CP037, CP500, CP930, CP939:
+		return enc, 0, nil
+	case UTF8M:
+		return UTF8, len(UTF8BOM), nil
+	case UTF16BEM:
+		return UTF16BE, len(UTF16BEBOM), nil
+	case UTF16LEM:
+		return UTF16LE, len(UTF16LEBOM), nil
+	}
+	return enc, 0, errors.New(fmt.Sprintf("random access is not supported in %s", enc))
+}
+
+// SourceRuneSize returns the byte size in the source of a rune read from a decoded text.
+// size is the byte size of the decoded rune.
+func SourceRuneSize(r rune, size int, enc Encoding) int {
+	switch enc {
+	case SJIS:
+		return sjisRuneByteSize(r)
+	case UTF16BE, UTF16LE, UTF16BEM, UTF16LEM, UTF16:
+		return utf16RuneByteSize(r)
+	case CP037, CP500, CP930, CP939:
+		return ebcdicRuneByteSize(r, enc)
+	}
+	return size
+}
+
+// ShiftCodeSize returns the size of the shift code preceding a rune read from a decoded text of CP930 or CP939,
+// and whether the rune is in the double-byte mode. shifted is the mode of the previous rune.
+//
+// A shift-out is counted before the first double-byte character of a run, and a shift-in before
+// the first single-byte character following the run, as the encoder writes them.
+func ShiftCodeSize(r rune, shifted bool, enc Encoding) (int, bool) {
+	if !IsShiftEncoding(enc) {
+		return 0, false
+	}
+	dbcs := 1 < ebcdicRuneByteSize(r, enc)
+	if dbcs == shifted {
+		return 0, shifted
+	}
+	return 1, dbcs
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
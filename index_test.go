@@ -0,0 +1,106 @@
+package text
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRecordIndex_Add(t *testing.T) {
+	idx := NewRecordIndex(2)
+	idx.Add(0, 3, 1)
+	idx.Add(1, 10, 2)
+	idx.Add(2, 20, 4)
+	idx.Add(2, 20, 4)
+	idx.Add(4, 40, 6)
+
+	expect := []IndexEntry{
+		{Record: 0, Offset: 3, Line: 1},
+		{Record: 2, Offset: 20, Line: 4},
+		{Record: 4, Offset: 40, Line: 6},
+	}
+	if !reflect.DeepEqual(idx.Entries, expect) {
+		t.Errorf("entries = %v, want %v", idx.Entries, expect)
+	}
+}
+
+var recordIndexLookupTests = []struct {
+	Record int
+	Expect IndexEntry
+	Ok     bool
+}{
+	{Record: 0, Ok: false},
+	{Record: 2, Expect: IndexEntry{Record: 2, Offset: 20, Line: 4}, Ok: true},
+	{Record: 3, Expect: IndexEntry{Record: 2, Offset: 20, Line: 4}, Ok: true},
+	{Record: 100, Expect: IndexEntry{Record: 4, Offset: 40, Line: 6}, Ok: true},
+}
+
+func TestRecordIndex_Lookup(t *testing.T) {
+	idx := &RecordIndex{
+		Interval: 2,
+		Entries: []IndexEntry{
+			{Record: 2, Offset: 20, Line: 4},
+			{Record: 4, Offset: 40, Line: 6},
+		},
+	}
+
+	for _, v := range recordIndexLookupTests {
+		entry, ok := idx.Lookup(v.Record)
+		if ok != v.Ok {
+			t.Errorf("record %d: ok = %t, want %t", v.Record, ok, v.Ok)
+		}
+		if entry != v.Expect {
+			t.Errorf("record %d: entry = %v, want %v", v.Record, entry, v.Expect)
+		}
+	}
+}
+
+func TestReadRecordIndex(t *testing.T) {
+	idx := NewRecordIndex(1000)
+	for i := 0; i < 5000; i++ {
+		idx.Add(i, int64(i)*1234567, i*3+1)
+	}
+
+	buf := new(bytes.Buffer)
+	n, err := idx.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("written size = %d, want %d", n, buf.Len())
+	}
+
+	result, err := ReadRecordIndex(buf)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(result, idx) {
+		t.Errorf("index = %v, want %v", result, idx)
+	}
+
+	for _, input := range []string{"", "GTRX\x01\x01\x00", "GTRI\x01\x01\x02\x00"} {
+		if _, err = ReadRecordIndex(bytes.NewReader([]byte(input))); err != ErrInvalidRecordIndex {
+			t.Errorf("error = %v for %q, want %v", err, input, ErrInvalidRecordIndex)
+		}
+	}
+}
+
+func TestShiftCodeSize(t *testing.T) {
+	shifted := false
+	sizes := make([]int, 0, 5)
+	for _, r := range "A日本B語" {
+		var size int
+		size, shifted = ShiftCodeSize(r, shifted, CP930)
+		sizes = append(sizes, size)
+	}
+	if expect := []int{0, 1, 0, 1, 1}; !reflect.DeepEqual(sizes, expect) {
+		t.Errorf("sizes = %v, want %v", sizes, expect)
+	}
+	if !shifted {
+		t.Errorf("shifted = %t, want %t", shifted, true)
+	}
+
+	if size, shifted := ShiftCodeSize('日', false, UTF8); size != 0 || shifted {
+		t.Errorf("size = %d, shifted = %t for UTF8, want 0, false", size, shifted)
+	}
+}
@@ -0,0 +1,62 @@
+// Package source provides the access to the undecoded source of readers.
+package source
+
+import (
+	"io"
+)
+
+// Tap is an io.Reader that keeps the bytes read from the underlying reader while Enabled is true.
+type Tap struct {
+	Enabled bool
+
+	reader io.Reader
+	buf    []byte
+	base   int64
+}
+
+func NewTap(r io.Reader) *Tap {
+	return &Tap{
+		reader: r,
+	}
+}
+
+func (t *Tap) Read(p []byte) (int, error) {
+	n, err := t.reader.Read(p)
+	if t.Enabled {
+		t.buf = append(t.buf, p[:n]...)
+	} else {
+		t.base = t.base + int64(len(t.buf)) + int64(n)
+		t.buf = t.buf[:0]
+	}
+	return n, err
+}
+
+// SetOffset sets the offset in the source of the next byte read.
+func (t *Tap) SetOffset(offset int64) {
+	t.base = offset
+	t.buf = t.buf[:0]
+}
+
+// Discard drops the bytes before the offset.
+func (t *Tap) Discard(offset int64) {
+	n := offset - t.base
+	if n <= 0 {
+		return
+	}
+	if int64(len(t.buf)) < n {
+		n = int64(len(t.buf))
+	}
+	t.buf = append(t.buf[:0], t.buf[n:]...)
+	t.base = t.base + n
+}
+
+// Bytes returns a copy of the bytes from start to end offsets,
+// or nil if the bytes are not kept.
+func (t *Tap) Bytes(start int64, end int64) []byte {
+	if start < t.base || end < start || t.base+int64(len(t.buf)) < end {
+		return nil
+	}
+	b := make([]byte, end-start)
+	copy(b, t.buf[start-t.base:end-t.base])
+	return b
+}
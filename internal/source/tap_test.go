@@ -0,0 +1,31 @@
+package source
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTap(t *testing.T) {
+	tap := NewTap(strings.NewReader("abcdefghij"))
+	tap.SetOffset(10)
+
+	p := make([]byte, 3)
+	_, _ = tap.Read(p)
+
+	tap.Enabled = true
+	_, _ = tap.Read(p)
+	_, _ = tap.Read(p)
+
+	if b := tap.Bytes(13, 16); !reflect.DeepEqual(b, []byte("def")) {
+		t.Errorf("bytes = %q, want %q", b, "def")
+	}
+	if b := tap.Bytes(12, 14); b != nil {
+		t.Errorf("bytes = %q, want nil", b)
+	}
+
+	tap.Discard(15)
+	if b := tap.Bytes(15, 19); !reflect.DeepEqual(b, []byte("fghi")) {
+		t.Errorf("bytes = %q, want %q", b, "fghi")
+	}
+}
@@ -0,0 +1,317 @@
+// Package structs provides the conversion between struct fields and text values
+// shared by the Unmarshal and Marshal functions of the sub packages.
+//
+// Struct fields are configured with the "text" tag:
+//
+//	Name string    `text:"name"`
+//	Date time.Time `text:"date,format=2006-01-02"`
+//	Code string    `text:"code,pos=0:5,align=right"`
+//	Memo string    `text:"-"`
+//
+// The format option must be the last option because a layout may contain commas.
+package structs
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mithrandie/go-text"
+)
+
+const TagName = "text"
+
+const DefaultTimeFormat = time.RFC3339Nano
+
+var timeType = reflect.TypeOf(time.Time{})
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+type Field struct {
+	Name      string
+	Index     []int
+	Format    string
+	Start     int
+	End       int
+	Alignment text.FieldAlignment
+	Quote     bool
+}
+
+func (f Field) HasPosition() bool {
+	return -1 < f.Start
+}
+
+func Fields(t reflect.Type) ([]Field, error) {
+	fields := make([]Field, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tag := sf.Tag.Get(TagName)
+		if tag == "-" {
+			continue
+		}
+
+		field := Field{
+			Name:  sf.Name,
+			Index: sf.Index,
+			Start: -1,
+			End:   -1,
+		}
+		if err := parseTag(tag, &field); err != nil {
+			return nil, errors.New(fmt.Sprintf("field %s: %s", sf.Name, err.Error()))
+		}
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+func parseTag(tag string, field *Field) error {
+	if len(tag) < 1 {
+		return nil
+	}
+
+	if i := strings.Index(tag, ",format="); -1 < i {
+		field.Format = tag[i+len(",format="):]
+		tag = tag[:i]
+	}
+	opts := strings.Split(tag, ",")
+
+	if 0 < len(opts[0]) {
+		field.Name = opts[0]
+	}
+
+	for _, opt := range opts[1:] {
+		key, value := opt, ""
+		if i := strings.Index(opt, "="); -1 < i {
+			key, value = opt[:i], opt[i+1:]
+		}
+
+		switch key {
+		case "quote":
+			field.Quote = true
+		case "pos":
+			se := strings.Split(value, ":")
+			if len(se) != 2 {
+				return errors.New(fmt.Sprintf("invalid position: %q", value))
+			}
+			start, err := strconv.Atoi(se[0])
+			if err != nil {
+				return errors.New(fmt.Sprintf("invalid position: %q", value))
+			}
+			end, err := strconv.Atoi(se[1])
+			if err != nil || start < 0 || end <= start {
+				return errors.New(fmt.Sprintf("invalid position: %q", value))
+			}
+			field.Start = start
+			field.End = end
+		case "align":
+			switch strings.ToLower(value) {
+			case "left":
+				field.Alignment = text.LeftAligned
+			case "right":
+				field.Alignment = text.RightAligned
+			case "center":
+				field.Alignment = text.Centering
+			default:
+				return errors.New(fmt.Sprintf("invalid alignment: %q", value))
+			}
+		default:
+			return errors.New(fmt.Sprintf("unknown option: %q", opt))
+		}
+	}
+	return nil
+}
+
+// SliceOf returns the slice pointed to by v and the struct type of its elements.
+func SliceOf(v interface{}) (reflect.Value, reflect.Type, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, nil, errors.New(fmt.Sprintf("non-nil pointer to a slice of structs is required, got %T", v))
+	}
+	t, err := elemStruct(rv.Elem().Type().Elem(), v)
+	return rv.Elem(), t, err
+}
+
+// StructOf returns the struct type of the elements of the slice v.
+func StructOf(v interface{}) (reflect.Type, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice {
+		return nil, errors.New(fmt.Sprintf("slice of structs is required, got %T", v))
+	}
+	return elemStruct(rv.Type().Elem(), v)
+}
+
+func elemStruct(t reflect.Type, v interface{}) (reflect.Type, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, errors.New(fmt.Sprintf("slice of structs is required, got %T", v))
+	}
+	return t, nil
+}
+
+// AppendElem appends a new zero element to the slice and returns the addressable struct value.
+func AppendElem(slice reflect.Value) reflect.Value {
+	et := slice.Type().Elem()
+	if et.Kind() == reflect.Ptr {
+		ptr := reflect.New(et.Elem())
+		slice.Set(reflect.Append(slice, ptr))
+		return ptr.Elem()
+	}
+	slice.Set(reflect.Append(slice, reflect.Zero(et)))
+	return slice.Index(slice.Len() - 1)
+}
+
+// Elem returns the struct value of the i-th element of the slice, or false if the element is a nil pointer.
+func Elem(slice reflect.Value, i int) (reflect.Value, bool) {
+	v := slice.Index(i)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+// Decode stores the text value into v.
+// A nil value, or an empty value for non-string types, is stored as the zero value or a nil pointer.
+func Decode(src text.RawText, v reflect.Value, format string) error {
+	if v.Kind() == reflect.Ptr {
+		if src == nil || (len(src) < 1 && !acceptsEmpty(v.Type().Elem())) {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return Decode(src, v.Elem(), format)
+	}
+
+	if src == nil || (len(src) < 1 && !acceptsEmpty(v.Type())) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		if len(format) < 1 {
+			format = DefaultTimeFormat
+		}
+		t, err := time.Parse(format, string(src))
+		if err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if reflect.PtrTo(v.Type()).Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(src)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(string(src))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(string(src))
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(string(src), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(string(src), 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(string(src), v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return errors.New(fmt.Sprintf("unsupported type: %s", v.Type()))
+		}
+		b := make([]byte, len(src))
+		copy(b, src)
+		v.SetBytes(b)
+	default:
+		return errors.New(fmt.Sprintf("unsupported type: %s", v.Type()))
+	}
+	return nil
+}
+
+func acceptsEmpty(t reflect.Type) bool {
+	if t == timeType {
+		return false
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Kind() == reflect.String || (t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8)
+}
+
+// Encode returns the text representation of v. The second value is true if v is a nil pointer.
+func Encode(v reflect.Value, format string) (string, bool, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", true, nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		if len(format) < 1 {
+			format = DefaultTimeFormat
+		}
+		return v.Interface().(time.Time).Format(format), false, nil
+	}
+
+	if v.Type().Implements(textMarshalerType) {
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), false, err
+	}
+	if v.CanAddr() && reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		b, err := v.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), false, err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), false, nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), false, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), false, nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes()), false, nil
+		}
+	}
+	return "", false, errors.New(fmt.Sprintf("unsupported type: %s", v.Type()))
+}
@@ -0,0 +1,130 @@
+package structs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mithrandie/go-text"
+)
+
+type upperText string
+
+func (u *upperText) UnmarshalText(b []byte) error {
+	*u = upperText(strings.ToUpper(string(b)))
+	return nil
+}
+
+func (u upperText) MarshalText() ([]byte, error) {
+	return []byte(strings.ToLower(string(u))), nil
+}
+
+type testStruct struct {
+	Name     string     `text:"name"`
+	Age      int        `text:"age,pos=0:3,align=right"`
+	Rate     *float64   `text:"rate"`
+	Active   bool       `text:"active,quote"`
+	Code     upperText  `text:"code"`
+	Date     time.Time  `text:"date,format=Mon, 02 Jan 2006"`
+	Time     *time.Time `text:"time"`
+	Ignored  string     `text:"-"`
+	Untagged uint8
+	private  string
+}
+
+func TestFields(t *testing.T) {
+	expect := []Field{
+		{Name: "name", Index: []int{0}, Start: -1, End: -1},
+		{Name: "age", Index: []int{1}, Start: 0, End: 3, Alignment: text.RightAligned},
+		{Name: "rate", Index: []int{2}, Start: -1, End: -1},
+		{Name: "active", Index: []int{3}, Start: -1, End: -1, Quote: true},
+		{Name: "code", Index: []int{4}, Start: -1, End: -1},
+		{Name: "date", Index: []int{5}, Start: -1, End: -1, Format: "Mon, 02 Jan 2006"},
+		{Name: "time", Index: []int{6}, Start: -1, End: -1},
+		{Name: "Untagged", Index: []int{8}, Start: -1, End: -1},
+	}
+
+	fields, err := Fields(reflect.TypeOf(testStruct{}))
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(fields, expect) {
+		t.Errorf("fields = %v, want %v", fields, expect)
+	}
+
+	expectErr := "field A: invalid position: \"3:1\""
+	_, err = Fields(reflect.TypeOf(struct {
+		A string `text:"a,pos=3:1"`
+	}{}))
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+func TestDecodeAndEncode(t *testing.T) {
+	record := []text.RawText{
+		text.RawText("abc"),
+		text.RawText("-12"),
+		text.RawText("1.5"),
+		text.RawText("true"),
+		text.RawText("xyz"),
+		text.RawText("Fri, 03 Feb 2012"),
+		nil,
+		nil,
+		text.RawText("255"),
+	}
+	rate := 1.5
+	expect := testStruct{
+		Name:     "abc",
+		Age:      -12,
+		Rate:     &rate,
+		Active:   true,
+		Code:     "XYZ",
+		Date:     time.Date(2012, 2, 3, 0, 0, 0, 0, time.UTC),
+		Untagged: 255,
+	}
+	expectText := []string{"abc", "-12", "1.5", "true", "xyz", "Fri, 03 Feb 2012", "", "", "255"}
+
+	fields, _ := Fields(reflect.TypeOf(testStruct{}))
+	v := reflect.New(reflect.TypeOf(testStruct{})).Elem()
+	for _, f := range fields {
+		if err := Decode(record[f.Index[0]], v.FieldByIndex(f.Index), f.Format); err != nil {
+			t.Fatalf("field %s: unexpected error %q", f.Name, err.Error())
+		}
+	}
+	if !reflect.DeepEqual(v.Interface(), expect) {
+		t.Errorf("result = %#v, want %#v", v.Interface(), expect)
+	}
+
+	for _, f := range fields {
+		s, isNull, err := Encode(v.FieldByIndex(f.Index), f.Format)
+		if err != nil {
+			t.Fatalf("field %s: unexpected error %q", f.Name, err.Error())
+		}
+		if s != expectText[f.Index[0]] {
+			t.Errorf("field %s: result = %q, want %q", f.Name, s, expectText[f.Index[0]])
+		}
+		if isNull != (f.Name == "time") {
+			t.Errorf("field %s: null = %t, want %t", f.Name, isNull, f.Name == "time")
+		}
+	}
+
+	var i int
+	expectErr := "strconv.ParseInt: parsing \"a\": invalid syntax"
+	err := Decode(text.RawText("a"), reflect.ValueOf(&i).Elem(), "")
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+
+	i = 5
+	if err = Decode(text.RawText{}, reflect.ValueOf(&i).Elem(), ""); err != nil {
+		t.Errorf("unexpected error %q", err.Error())
+	} else if i != 0 {
+		t.Errorf("result = %d, want %d", i, 0)
+	}
+}
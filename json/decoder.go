@@ -27,7 +27,7 @@ func (e DecodeError) Line() int {
 }
 
 func (e DecodeError) Char() int {
-	return e.line
+	return e.char
 }
 
 func (e DecodeError) Message() string {
@@ -238,3 +238,20 @@ func TestDecoder_Decode(t *testing.T) {
 		}
 	}
 }
+
+func TestDecodeError(t *testing.T) {
+	_, _, err := NewDecoder().Decode("{\n  \"key\" 1}")
+	e, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("error = %#v, want *DecodeError", err)
+	}
+	if e.Line() != 2 {
+		t.Errorf("line = %d, want %d", e.Line(), 2)
+	}
+	if e.Char() != 9 {
+		t.Errorf("char = %d, want %d", e.Char(), 9)
+	}
+	if e.Message() != "unexpected token \"1\"" {
+		t.Errorf("message = %q, want %q", e.Message(), "unexpected token \"1\"")
+	}
+}
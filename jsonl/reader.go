@@ -2,14 +2,29 @@ package jsonl
 
 import (
 	"bufio"
-	"github.com/mithrandie/go-text/json"
+	"bytes"
 	"io"
+
+	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/json"
 )
 
 type Reader struct {
-	reader *bufio.Reader
-	line   int
-	pos    int
+	// Limits restricts resources consumed by hostile input.
+	// The size of a record is the length of a line, a field is a string value or a key of an object,
+	// and the number of fields is the number of members or elements of the top-level structure.
+	Limits text.Limits
+
+	// Index records the position of every Interval-th record while reading.
+	// Record numbers do not count empty lines.
+	Index *text.RecordIndex
+
+	reader    *bufio.Reader
+	line      int
+	pos       int
+	records   int
+	skipUntil int
+	lineBuf   bytes.Buffer
 
 	decoder *json.Decoder
 }
@@ -23,12 +38,59 @@ func NewReader(r io.Reader) *Reader {
 	}
 }
 
+// NewIndexedReader returns a reader positioned at the record using the index.
+// Records from the nearest indexed record are skipped on the first call to Read.
+func NewIndexedReader(r io.ReadSeeker, index *text.RecordIndex, record int) (*Reader, error) {
+	entry, ok := index.Lookup(record)
+	if !ok {
+		entry = text.IndexEntry{Line: 1}
+	}
+	if _, err := r.Seek(entry.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	reader := NewReader(r)
+	reader.line = entry.Line - 1
+	reader.pos = int(entry.Offset)
+	reader.records = entry.Record
+	reader.skipUntil = record
+	return reader, nil
+}
+
 func (r *Reader) SetUseInteger(useInteger bool) {
 	r.decoder.UseInteger = useInteger
 }
 
+// Read returns the next structure.
+//
+// Syntax errors are returned as *text.ParseError instead of *json.DecodeError.
+// The *json.DecodeError with the line number in the source is wrapped, and can be retrieved with errors.As.
 func (r *Reader) Read() (json.Structure, json.EscapeType, error) {
-	line, err := r.reader.ReadString('\n')
+	for {
+		offset := r.pos
+		st, et, err := r.read()
+		if err != nil {
+			return st, et, err
+		}
+		if st == nil {
+			if r.records < r.skipUntil {
+				continue
+			}
+			return st, et, nil
+		}
+
+		if r.Index != nil {
+			r.Index.Add(r.records-1, int64(offset), r.line)
+		}
+		if r.records <= r.skipUntil {
+			continue
+		}
+		return st, et, nil
+	}
+}
+
+func (r *Reader) read() (json.Structure, json.EscapeType, error) {
+	line, err := r.readLine()
 
 	if err == io.EOF {
 		if len(line) < 1 {
@@ -42,18 +104,50 @@ func (r *Reader) Read() (json.Structure, json.EscapeType, error) {
 	}
 
 	r.line++
+	offset := r.pos
 	r.pos = r.pos + len(line)
 
 	st, et, err := r.decoder.Decode(line)
 	if err != nil {
 		if e, ok := err.(*json.DecodeError); ok {
-			err = json.NewDecodeError(r.line, e.Char(), e.Message())
+			derr := json.NewDecodeError(r.line, e.Char(), e.Message())
+			err = &text.ParseError{
+				Kind:    text.SyntaxError,
+				Line:    r.line,
+				Column:  e.Char(),
+				Offset:  int64(offset + byteIndex(line, e.Char()-1)),
+				Record:  r.records,
+				Message: e.Message(),
+				Err:     derr,
+			}
 		}
 		return nil, et, err
 	}
+
+	if st != nil {
+		if r.Limits.RecordsExceeded(r.records + 1) {
+			return nil, et, r.newLimitError(text.RecordsLimit, r.line)
+		}
+		if err = r.checkFields(st); err != nil {
+			return nil, et, err
+		}
+		r.records++
+	}
 	return st, et, nil
 }
 
+func (r *Reader) newLimitError(limit text.Limit, line int) *text.ParseError {
+	lerr := r.Limits.NewLimitError(limit, line)
+	return &text.ParseError{
+		Kind:    text.LimitExceededError,
+		Line:    line,
+		Offset:  int64(r.pos),
+		Record:  r.records,
+		Message: lerr.Message(),
+		Err:     lerr,
+	}
+}
+
 func (r *Reader) ReadAll() ([]json.Structure, json.EscapeType, error) {
 	lines := make([]json.Structure, 0, 160)
 	escapeType := json.Backslash
@@ -83,3 +177,73 @@ func (r *Reader) ReadAll() ([]json.Structure, json.EscapeType, error) {
 func (r *Reader) Pos() int {
 	return r.pos
 }
+
+func (r *Reader) readLine() (string, error) {
+	r.lineBuf.Reset()
+
+	for {
+		b, err := r.reader.ReadSlice('\n')
+		r.lineBuf.Write(b)
+
+		size := r.lineBuf.Len()
+		if 0 < size && r.lineBuf.Bytes()[size-1] == '\n' {
+			size--
+		}
+		if r.Limits.RecordSizeExceeded(size) {
+			return "", r.newLimitError(text.RecordSizeLimit, r.line+1)
+		}
+
+		if err != bufio.ErrBufferFull {
+			return r.lineBuf.String(), err
+		}
+	}
+}
+
+func (r *Reader) checkFields(st json.Structure) error {
+	switch v := st.(type) {
+	case json.Object:
+		if r.Limits.FieldsPerRecordExceeded(v.Len()) {
+			return r.newLimitError(text.FieldsPerRecordLimit, r.line)
+		}
+	case json.Array:
+		if r.Limits.FieldsPerRecordExceeded(len(v)) {
+			return r.newLimitError(text.FieldsPerRecordLimit, r.line)
+		}
+	}
+
+	if 0 < r.Limits.MaxFieldSize && !r.withinFieldSize(st) {
+		return r.newLimitError(text.FieldSizeLimit, r.line)
+	}
+	return nil
+}
+
+func (r *Reader) withinFieldSize(st json.Structure) bool {
+	switch v := st.(type) {
+	case json.Object:
+		for _, m := range v.Members {
+			if r.Limits.FieldSizeExceeded(len(m.Key)) || !r.withinFieldSize(m.Value) {
+				return false
+			}
+		}
+	case json.Array:
+		for _, e := range v {
+			if !r.withinFieldSize(e) {
+				return false
+			}
+		}
+	case json.String:
+		return !r.Limits.FieldSizeExceeded(len(v))
+	}
+	return true
+}
+
+// byteIndex returns the byte index of the n-th rune in s.
+func byteIndex(s string, n int) int {
+	for i := range s {
+		if n < 1 {
+			return i
+		}
+		n--
+	}
+	return len(s)
+}
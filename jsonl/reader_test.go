@@ -1,6 +1,8 @@
 package jsonl
 
 import (
+	"errors"
+	"github.com/mithrandie/go-text"
 	"github.com/mithrandie/go-text/json"
 	"reflect"
 	"strings"
@@ -189,3 +191,127 @@ func TestReader_ReadAll(t *testing.T) {
 		}
 	}
 }
+
+var readerReadAllWithLimitsTests = []struct {
+	Label  string
+	Limits text.Limits
+	Input  string
+	Error  string
+}{
+	{
+		Label:  "Within Limits",
+		Limits: text.Limits{MaxFieldSize: 3, MaxRecordSize: 21, MaxFieldsPerRecord: 2, MaxRecords: 2},
+		Input:  "{\"a\":\"aaa\",\"b\":[1,2]}\n[\"abc\",{\"d\":1}]\n",
+	},
+	{
+		Label:  "Field Size",
+		Limits: text.Limits{MaxFieldSize: 3},
+		Input:  "{\"a\":\"aaa\"}\n{\"a\":{\"b\":\"bbbb\"}}",
+		Error:  "line 2: field size exceeds the limit of 3",
+	},
+	{
+		Label:  "Record Size",
+		Limits: text.Limits{MaxRecordSize: 10},
+		Input:  "{\"a\":\"aaa\"}\n",
+		Error:  "line 1: record size exceeds the limit of 10",
+	},
+	{
+		Label:  "Fields Per Record",
+		Limits: text.Limits{MaxFieldsPerRecord: 2},
+		Input:  "[1,2]\n[1,2,3]",
+		Error:  "line 2: number of fields in record exceeds the limit of 2",
+	},
+	{
+		Label:  "Records",
+		Limits: text.Limits{MaxRecords: 2},
+		Input:  "1\n2\n3\n",
+		Error:  "line 3: number of records exceeds the limit of 2",
+	},
+}
+
+func TestReader_ReadAllWithLimits(t *testing.T) {
+	for _, v := range readerReadAllWithLimitsTests {
+		r := NewReader(strings.NewReader(v.Input))
+		r.Limits = v.Limits
+
+		_, _, err := r.ReadAll()
+
+		if err != nil {
+			if len(v.Error) < 1 {
+				t.Errorf("unexpected error %q for %q", err.Error(), v.Label)
+			} else if err.Error() != v.Error {
+				t.Errorf("error %q, want error %q for %q", err, v.Error, v.Label)
+			} else if !errors.As(err, new(*text.LimitError)) {
+				t.Errorf("error type %T, want *text.LimitError for %q", err, v.Label)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("no error, want error %q for %q", v.Error, v.Label)
+		}
+	}
+}
+
+func TestNewIndexedReader(t *testing.T) {
+	input := "{\"a\":\"日本\"}\n\n[1,2]\n\"abc\"\n{\"a\":2}"
+
+	r := NewReader(strings.NewReader(input))
+	r.Index = text.NewRecordIndex(2)
+	records, _, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	expectEntries := []text.IndexEntry{
+		{Record: 0, Offset: 0, Line: 1},
+		{Record: 2, Offset: 22, Line: 4},
+	}
+	if !reflect.DeepEqual(r.Index.Entries, expectEntries) {
+		t.Errorf("entries = %v, want %v", r.Index.Entries, expectEntries)
+	}
+
+	for i := range records {
+		ir, err := NewIndexedReader(strings.NewReader(input), r.Index, i)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+		result, _, err := ir.ReadAll()
+		if err != nil {
+			t.Errorf("record %d: unexpected error %q", i, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(result, records[i:]) {
+			t.Errorf("record %d: records = %#v, want %#v", i, result, records[i:])
+		}
+		if ir.Pos() < int(r.Index.Entries[i/2].Offset) {
+			t.Errorf("record %d: pos = %d, want at least %d", i, ir.Pos(), r.Index.Entries[i/2].Offset)
+		}
+	}
+}
+
+func TestReader_ReadParseError(t *testing.T) {
+	input := "{\"a\":1}\n\n{\"b\":\"日本\" 2}\n"
+	expect := &text.ParseError{
+		Kind:    text.SyntaxError,
+		Line:    3,
+		Column:  11,
+		Offset:  23,
+		Record:  1,
+		Message: "unexpected token \"2\"",
+		Err:     json.NewDecodeError(3, 11, "unexpected token \"2\""),
+	}
+
+	r := NewReader(strings.NewReader(input))
+	_, _, err := r.ReadAll()
+
+	var perr *text.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *text.ParseError", err)
+	}
+	if !reflect.DeepEqual(perr, expect) {
+		t.Errorf("error = %#v, want %#v", perr, expect)
+	}
+	if !errors.As(err, new(*json.DecodeError)) {
+		t.Errorf("error does not wrap *json.DecodeError")
+	}
+}
@@ -0,0 +1,85 @@
+package text
+
+import (
+	"fmt"
+)
+
+type Limit int
+
+const (
+	FieldSizeLimit Limit = iota
+	RecordSizeLimit
+	FieldsPerRecordLimit
+	RecordsLimit
+)
+
+var LimitLiteral = map[Limit]string{
+	FieldSizeLimit:       "field size",
+	RecordSizeLimit:      "record size",
+	FieldsPerRecordLimit: "number of fields in record",
+	RecordsLimit:         "number of records",
+}
+
+func (l Limit) String() string {
+	return LimitLiteral[l]
+}
+
+// Limits restricts resources consumed by readers. Zero means unlimited.
+// Sizes are measured in bytes of UTF-8 decoded text.
+type Limits struct {
+	MaxFieldSize       int
+	MaxRecordSize      int
+	MaxFieldsPerRecord int
+	MaxRecords         int
+}
+
+func (l Limits) FieldSizeExceeded(size int) bool {
+	return 0 < l.MaxFieldSize && l.MaxFieldSize < size
+}
+
+func (l Limits) RecordSizeExceeded(size int) bool {
+	return 0 < l.MaxRecordSize && l.MaxRecordSize < size
+}
+
+func (l Limits) FieldsPerRecordExceeded(n int) bool {
+	return 0 < l.MaxFieldsPerRecord && l.MaxFieldsPerRecord < n
+}
+
+func (l Limits) RecordsExceeded(n int) bool {
+	return 0 < l.MaxRecords && l.MaxRecords < n
+}
+
+// NewLimitError returns an error for the limit. The maximum value is taken from limits.
+func (l Limits) NewLimitError(limit Limit, line int) *LimitError {
+	max := 0
+	switch limit {
+	case FieldSizeLimit:
+		max = l.MaxFieldSize
+	case RecordSizeLimit:
+		max = l.MaxRecordSize
+	case FieldsPerRecordLimit:
+		max = l.MaxFieldsPerRecord
+	case RecordsLimit:
+		max = l.MaxRecords
+	}
+
+	return &LimitError{
+		Limit: limit,
+		Max:   max,
+		Line:  line,
+	}
+}
+
+type LimitError struct {
+	Limit Limit
+	Max   int
+	Line  int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message())
+}
+
+func (e *LimitError) Message() string {
+	return fmt.Sprintf("%s exceeds the limit of %d", e.Limit, e.Max)
+}
@@ -0,0 +1,36 @@
+package text
+
+import (
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	limits := Limits{
+		MaxFieldSize:       10,
+		MaxRecordSize:      100,
+		MaxFieldsPerRecord: 0,
+		MaxRecords:         5,
+	}
+
+	if limits.FieldSizeExceeded(10) {
+		t.Errorf("field size 10 exceeds the limit, want not to exceed")
+	}
+	if !limits.FieldSizeExceeded(11) {
+		t.Errorf("field size 11 does not exceed the limit, want to exceed")
+	}
+	if !limits.RecordSizeExceeded(101) {
+		t.Errorf("record size 101 does not exceed the limit, want to exceed")
+	}
+	if limits.FieldsPerRecordExceeded(1000000) {
+		t.Errorf("fields 1000000 exceeds the unlimited limit, want not to exceed")
+	}
+	if !limits.RecordsExceeded(6) {
+		t.Errorf("records 6 does not exceed the limit, want to exceed")
+	}
+
+	expect := "line 3: record size exceeds the limit of 100"
+	err := limits.NewLimitError(RecordSizeLimit, 3)
+	if err.Error() != expect {
+		t.Errorf("error = %q, want %q", err.Error(), expect)
+	}
+}
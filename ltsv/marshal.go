@@ -0,0 +1,131 @@
+package ltsv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/internal/structs"
+)
+
+// StructLabels returns the labels of the struct type of the elements of the slice v.
+// The labels can be used as the header of a Writer passed to Marshal.
+func StructLabels(v interface{}) ([]string, error) {
+	t, err := structs.StructOf(v)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := structs.Fields(t)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]string, len(fields))
+	for i, f := range fields {
+		labels[i] = f.Name
+	}
+	return labels, nil
+}
+
+// Unmarshal reads all records from r, and stores them into the slice pointed to by v.
+// Struct fields are mapped to the labels specified in the "text" tag or the field names.
+func Unmarshal(r *Reader, v interface{}) error {
+	slice, t, err := structs.SliceOf(v)
+	if err != nil {
+		return err
+	}
+	fields, err := structs.Fields(t)
+	if err != nil {
+		return err
+	}
+
+	columns := make([]int, len(fields))
+	for i := range columns {
+		columns[i] = -1
+	}
+
+	for n := 1; ; n++ {
+		record, err := r.parseRecord(false)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		elem := structs.AppendElem(slice)
+		for i, f := range fields {
+			if columns[i] < 0 {
+				columns[i] = indexOfLabel(r.Header.Fields(), f.Name)
+			}
+			if columns[i] < 0 || len(record) <= columns[i] {
+				continue
+			}
+			if err = structs.Decode(record[columns[i]], elem.FieldByIndex(f.Index), f.Format); err != nil {
+				return errors.New(fmt.Sprintf("record %d, field %s: %s", n, f.Name, err.Error()))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Marshal writes the elements of the slice v to w.
+// Labels of the writer that do not exist in the struct are written as empty values.
+func Marshal(w *Writer, v interface{}) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	t, err := structs.StructOf(v)
+	if err != nil {
+		return err
+	}
+	fields, err := structs.Fields(t)
+	if err != nil {
+		return err
+	}
+
+	columns := make([]int, len(fields))
+	for i, f := range fields {
+		columns[i] = indexOfLabel(w.header, f.Name)
+	}
+
+	record := make([]text.RawText, len(w.header))
+	for i := 0; i < rv.Len(); i++ {
+		for j := range record {
+			record[j] = text.RawText{}
+		}
+
+		if elem, ok := structs.Elem(rv, i); ok {
+			for j, f := range fields {
+				if columns[j] < 0 {
+					continue
+				}
+				s, isNull, err := structs.Encode(elem.FieldByIndex(f.Index), f.Format)
+				if err != nil {
+					return errors.New(fmt.Sprintf("field %s: %s", f.Name, err.Error()))
+				}
+				if isNull {
+					record[columns[j]] = nil
+				} else {
+					record[columns[j]] = text.RawText(s)
+				}
+			}
+		}
+
+		if err = w.WriteRawText(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indexOfLabel(labels []string, label string) int {
+	for i, l := range labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
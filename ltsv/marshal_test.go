@@ -0,0 +1,72 @@
+package ltsv
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mithrandie/go-text"
+)
+
+type marshalTestRecord struct {
+	Host   string `text:"host"`
+	Status int    `text:"status"`
+	Size   *int   `text:"size"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	input := "host:127.0.0.1\tstatus:200\tsize:1024\nstatus:404\thost:example.com\treq:GET"
+	size := 1024
+	expect := []marshalTestRecord{
+		{Host: "127.0.0.1", Status: 200, Size: &size},
+		{Host: "example.com", Status: 404, Size: nil},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	var records []marshalTestRecord
+	if err := Unmarshal(r, &records); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(records, expect) {
+		t.Errorf("records = %v, want %v", records, expect)
+	}
+
+	input = "host:127.0.0.1\tstatus:OK"
+	expectErr := "record 1, field status: strconv.ParseInt: parsing \"OK\": invalid syntax"
+
+	r, _ = NewReader(strings.NewReader(input), text.UTF8)
+	err := Unmarshal(r, &records)
+	if err == nil {
+		t.Errorf("no error, want error %q", expectErr)
+	} else if err.Error() != expectErr {
+		t.Errorf("error = %q, want error %q", err.Error(), expectErr)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	size := 1024
+	records := []marshalTestRecord{
+		{Host: "127.0.0.1", Status: 200, Size: &size},
+		{Host: "example.com", Status: 404, Size: nil},
+	}
+	expect := "host:127.0.0.1\tstatus:200\tsize:1024\n" +
+		"host:example.com\tstatus:404\tsize:"
+
+	labels, err := StructLabels(records)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, labels, text.LF, text.UTF8)
+	if err = Marshal(w, records); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if err = w.Flush(); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+}
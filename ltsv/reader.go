@@ -3,11 +3,10 @@ package ltsv
 import (
 	"bufio"
 	"bytes"
-	"errors"
-	"fmt"
 	"io"
 
 	"github.com/mithrandie/go-text"
+	"github.com/mithrandie/go-text/internal/source"
 )
 
 type Header struct {
@@ -65,53 +64,226 @@ func (r Record) Clear() {
 type Reader struct {
 	WithoutNull bool
 
-	reader *bufio.Reader
-	line   int
-	column int
-
-	keyBuf   bytes.Buffer
-	valueBuf bytes.Buffer
-	record   Record
+	// ReuseRecord makes Read return a record sharing the reader's buffers.
+	// The record is valid only until the next call to Read.
+	ReuseRecord bool
+
+	// Limits restricts resources consumed by hostile input.
+	// The size of a field is the size of its value, and the size of a record
+	// is the total size of its labels, separators and values.
+	Limits text.Limits
+
+	// NullTokens are the values read as NULL in addition to empty values.
+	NullTokens text.NullTokens
+
+	// KeepSource keeps the original text of the last record read, which Source returns.
+	// It must be set before the first call to Read.
+	KeepSource bool
+
+	// Index records the position of every Interval-th record while reading.
+	// It must be set before the first call to Read.
+	Index *text.RecordIndex
+
+	reader     *bufio.Reader
+	tap        *source.Tap
+	encoding   text.Encoding
+	line       int
+	column     int
+	records    int
+	field      int
+	recordSize int
+
+	offset       int64
+	runeSize     int
+	recordOffset int64
+
+	// shifted is true in a run of double-byte characters of CP930 and CP939,
+	// and shiftSize is the size of the shift code counted for the last rune.
+	shifted       bool
+	prevShifted   bool
+	shiftSize     int
+	recordShifted bool
+	recordLine    int
+	skipUntil     int
+
+	keyBuf      bytes.Buffer
+	valueBuf    bytes.Buffer
+	fieldIndex  map[string]int
+	fieldValues [][]byte
+	lastRecord  []text.RawText
 
 	Header            *Header
 	DetectedLineBreak text.LineBreak
 }
 
 func NewReader(r io.Reader, enc text.Encoding) (*Reader, error) {
-	decoder, err := text.GetTransformDecoder(r, enc)
+	tap := source.NewTap(r)
+	decoder, err := text.GetTransformDecoder(tap, enc)
 	if err != nil {
 		return nil, err
 	}
+	_, bom, _ := text.SeekEncoding(enc)
 
 	return &Reader{
 		WithoutNull: false,
 		reader:      bufio.NewReader(decoder),
+		tap:         tap,
+		encoding:    enc,
 		line:        1,
 		column:      0,
+		offset:      int64(bom),
 		keyBuf:      bytes.Buffer{},
 		valueBuf:    bytes.Buffer{},
-		record:      make(Record),
+		fieldIndex:  make(map[string]int, 32),
+		fieldValues: make([][]byte, 0, 32),
 		Header:      NewHeader(),
 	}, nil
 }
 
-func (r *Reader) newError(s string) error {
-	return errors.New(fmt.Sprintf("line %d, column %d: %s", r.line, r.column, s))
+// NewIndexedReader returns a reader positioned at the record using the index.
+// Records from the nearest indexed record are skipped on the first call to Read.
+//
+// Header contains only the labels that appear in the records read by the returned reader.
+func NewIndexedReader(r io.ReadSeeker, enc text.Encoding, index *text.RecordIndex, record int) (*Reader, error) {
+	seekEnc, _, err := text.SeekEncoding(enc)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := index.Lookup(record)
+	if !ok || entry.Offset < 1 {
+		if _, err = r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		reader, err := NewReader(r, enc)
+		if err != nil {
+			return nil, err
+		}
+		reader.skipUntil = record
+		return reader, nil
+	}
+
+	if _, err = r.Seek(entry.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	reader, err := NewReader(r, seekEnc)
+	if err != nil {
+		return nil, err
+	}
+	reader.encoding = enc
+	reader.line = entry.Line
+	reader.records = entry.Record
+	reader.offset = entry.Offset
+	reader.tap.SetOffset(entry.Offset)
+	reader.skipUntil = record
+	return reader, nil
+}
+
+func (r *Reader) newError(kind text.ErrorKind, line int, column int, s string) *text.ParseError {
+	return &text.ParseError{
+		Kind:    kind,
+		Line:    line,
+		Column:  column,
+		Offset:  r.offset,
+		Record:  r.records,
+		Field:   r.field,
+		Message: s,
+	}
+}
+
+func (r *Reader) newLimitError(limit text.Limit, line int) *text.ParseError {
+	lerr := r.Limits.NewLimitError(limit, line)
+	perr := r.newError(text.LimitExceededError, line, 0, lerr.Message())
+	perr.Err = lerr
+	return perr
 }
 
 func (r *Reader) Read() ([]text.RawText, error) {
-	r.record.Clear()
+	return r.parseRecord(r.ReuseRecord)
+}
+
+func (r *Reader) parseRecord(reuse bool) ([]text.RawText, error) {
+	for {
+		if err := r.parseFields(); err != nil {
+			return nil, err
+		}
+
+		if r.Limits.RecordsExceeded(r.records + 1) {
+			return nil, r.newLimitError(text.RecordsLimit, r.recordLine)
+		}
+		r.records++
+		if r.Index != nil && !r.recordShifted {
+			r.Index.Add(r.records-1, r.recordOffset, r.recordLine)
+		}
+		if r.records <= r.skipUntil {
+			continue
+		}
+		break
+	}
+
+	var values []text.RawText
+	if reuse {
+		if cap(r.lastRecord) < r.Header.Len() {
+			r.lastRecord = make([]text.RawText, r.Header.Len())
+		}
+		values = r.lastRecord[:r.Header.Len()]
+	} else {
+		values = make([]text.RawText, r.Header.Len())
+	}
+
+	for i, key := range r.Header.Fields() {
+		var b []byte
+		if idx, ok := r.fieldIndex[key]; ok {
+			b = r.fieldValues[idx]
+		}
+		if len(b) < 1 || (0 < len(r.NullTokens) && r.NullTokens.Match(b)) {
+			if r.WithoutNull {
+				values[i] = text.RawText{}
+			} else {
+				values[i] = nil
+			}
+		} else if reuse {
+			values[i] = b
+		} else {
+			v := make([]byte, len(b))
+			copy(v, b)
+			values[i] = v
+		}
+	}
+
+	return values, nil
+}
 
+func (r *Reader) parseFields() error {
+	r.tap.Enabled = r.KeepSource
+	for i := range r.fieldValues {
+		r.fieldValues[i] = r.fieldValues[i][:0]
+	}
+
+	r.recordSize = 0
 	fieldNum := 0
 	for {
+		r.field = fieldNum
+		if fieldNum < 1 {
+			r.recordOffset = r.offset
+			r.recordShifted = r.shifted
+			r.recordLine = r.line
+			if r.KeepSource {
+				r.tap.Discard(r.recordOffset)
+			}
+		}
+		if r.Limits.FieldsPerRecordExceeded(fieldNum + 1) {
+			return r.newLimitError(text.FieldsPerRecordLimit, r.line)
+		}
+
 		eol, err := r.parseField()
 		if err != nil {
 			if err == io.EOF {
 				if fieldNum < 1 {
-					return nil, io.EOF
+					return io.EOF
 				}
 			} else {
-				return nil, err
+				return err
 			}
 		}
 
@@ -119,11 +291,9 @@ func (r *Reader) Read() ([]text.RawText, error) {
 			continue
 		}
 
-		key := r.keyBuf.String()
-		if !r.Header.Exists(key) {
-			r.Header.Add(key)
-		}
-		r.record.Write(key, r.valueBuf.Bytes())
+		r.recordSize = r.recordSize + r.keyBuf.Len() + 1 + r.valueBuf.Len()
+		idx := r.indexOf(r.keyBuf.Bytes())
+		r.fieldValues[idx] = append(r.fieldValues[idx][:0], r.valueBuf.Bytes()...)
 
 		fieldNum++
 
@@ -132,28 +302,29 @@ func (r *Reader) Read() ([]text.RawText, error) {
 		}
 	}
 
-	values := make([]text.RawText, r.Header.Len())
-	for i, key := range r.Header.Fields() {
-		b, ok := r.record[key]
-		if !ok || len(b) < 1 {
-			if r.WithoutNull {
-				values[i] = text.RawText{}
-			}
-		} else {
-			v := make([]byte, len(b))
-			copy(v, b)
-			values[i] = v
-		}
+	return nil
+}
+
+func (r *Reader) indexOf(key []byte) int {
+	if idx, ok := r.fieldIndex[string(key)]; ok {
+		return idx
 	}
 
-	return values, nil
+	k := string(key)
+	if !r.Header.Exists(k) {
+		r.Header.Add(k)
+	}
+	idx := len(r.fieldValues)
+	r.fieldIndex[k] = idx
+	r.fieldValues = append(r.fieldValues, make([]byte, 0, 64))
+	return idx
 }
 
 func (r *Reader) ReadAll() ([][]text.RawText, error) {
 	records := make([][]text.RawText, 0)
 
 	for {
-		record, err := r.Read()
+		record, err := r.parseRecord(false)
 		if err == io.EOF {
 			break
 		}
@@ -176,6 +347,31 @@ func (r *Reader) ReadAll() ([][]text.RawText, error) {
 	return records, nil
 }
 
+// Source returns the original text of the last record read if KeepSource is true.
+func (r *Reader) Source() text.Source {
+	return text.NewSource(r.tap.Bytes(r.recordOffset, r.offset), r.encoding, r.recordLine, r.recordOffset)
+}
+
+func (r *Reader) readRune() (rune, error) {
+	ch, size, err := r.reader.ReadRune()
+	if err == nil {
+		r.runeSize = text.SourceRuneSize(ch, size, r.encoding)
+		r.prevShifted = r.shifted
+		r.shiftSize, r.shifted = text.ShiftCodeSize(ch, r.shifted, r.encoding)
+		r.offset = r.offset + int64(r.runeSize+r.shiftSize)
+	}
+	return ch, err
+}
+
+func (r *Reader) unreadRune() error {
+	if err := r.reader.UnreadRune(); err != nil {
+		return err
+	}
+	r.offset = r.offset - int64(r.runeSize+r.shiftSize)
+	r.shifted = r.prevShifted
+	return nil
+}
+
 func (r *Reader) parseField() (eol bool, err error) {
 	r.keyBuf.Reset()
 	r.valueBuf.Reset()
@@ -185,9 +381,16 @@ func (r *Reader) parseField() (eol bool, err error) {
 
 ParseFieldLoop:
 	for {
+		if r.Limits.FieldSizeExceeded(r.valueBuf.Len()) {
+			return eol, r.newLimitError(text.FieldSizeLimit, r.line)
+		}
+		if r.Limits.RecordSizeExceeded(r.recordSize + r.keyBuf.Len() + r.valueBuf.Len()) {
+			return eol, r.newLimitError(text.RecordSizeLimit, r.line)
+		}
+
 		lineBreak = ""
 
-		ch, _, e := r.reader.ReadRune()
+		ch, e := r.readRune()
 		r.column++
 
 		if e != nil {
@@ -200,11 +403,11 @@ ParseFieldLoop:
 
 		switch ch {
 		case '\r':
-			nextCh, _, _ := r.reader.ReadRune()
+			nextCh, _ := r.readRune()
 			if nextCh == '\n' {
 				lineBreak = text.CRLF
 			} else {
-				if err = r.reader.UnreadRune(); err != nil {
+				if err = r.unreadRune(); err != nil {
 					return eol, err
 				}
 				lineBreak = text.CR
@@ -237,7 +440,7 @@ ParseFieldLoop:
 	}
 
 	if 0 < r.keyBuf.Len() && readingKey {
-		err = r.newError("missing field separator")
+		err = r.newError(text.SeparatorError, r.line, r.column, "missing field separator")
 	}
 
 	return
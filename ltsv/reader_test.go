@@ -1,6 +1,8 @@
 package ltsv
 
 import (
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -167,3 +169,262 @@ func TestReader_ReadAll(t *testing.T) {
 		}
 	}
 }
+
+func TestReader_ReadWithReuseRecord(t *testing.T) {
+	input := "f1:v1\tf2:v2\tf3:v3\nf1:v4\tf3:v6\nf2:v8\tf1:v7\tf4:v10"
+	output := [][]text.RawText{
+		{text.RawText("v1"), text.RawText("v2"), text.RawText("v3")},
+		{text.RawText("v4"), nil, text.RawText("v6")},
+		{text.RawText("v7"), text.RawText("v8"), nil, text.RawText("v10")},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.ReuseRecord = true
+
+	records := make([][]text.RawText, 0, len(output))
+	var first []text.RawText
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+
+		if first == nil {
+			first = record
+		} else if len(record) <= cap(first) && &first[0] != &record[0] {
+			t.Errorf("record is not reused")
+		}
+
+		cp := make([]text.RawText, len(record))
+		for i := range record {
+			if record[i] != nil {
+				cp[i] = append(text.RawText{}, record[i]...)
+			}
+		}
+		records = append(records, cp)
+	}
+
+	if !reflect.DeepEqual(records, output) {
+		t.Errorf("records = %q, want %q", records, output)
+	}
+}
+
+var readerReadBenchmarkText = strings.Repeat("f1:aaaaaa\tf2:bbbbbb\tf3:cccccc\n", 10000)
+
+func BenchmarkReader_Read(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadBenchmarkText)
+		reader, _ := NewReader(r, text.UTF8)
+		for {
+			if _, err := reader.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func BenchmarkReader_ReadWithReuseRecord(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r := strings.NewReader(readerReadBenchmarkText)
+		reader, _ := NewReader(r, text.UTF8)
+		reader.ReuseRecord = true
+		for {
+			if _, err := reader.Read(); err != nil {
+				break
+			}
+		}
+	}
+}
+
+var readAllWithLimitsTests = []struct {
+	Name   string
+	Limits text.Limits
+	Input  string
+	Error  string
+}{
+	{
+		Name:   "Within Limits",
+		Limits: text.Limits{MaxFieldSize: 3, MaxRecordSize: 17, MaxFieldsPerRecord: 3, MaxRecords: 2},
+		Input:  "a:aaa\tb:bbb\tc:ccc\na:d\tb:e\tc:f",
+	},
+	{
+		Name:   "Field Size",
+		Limits: text.Limits{MaxFieldSize: 3},
+		Input:  "a:aaa\tb:bbb\na:d\tb:eeee",
+		Error:  "line 2: field size exceeds the limit of 3",
+	},
+	{
+		Name:   "Record Size",
+		Limits: text.Limits{MaxRecordSize: 8},
+		Input:  "a:aaa\tb:bbb\tc:ccc\na:d\tb:e\tc:f",
+		Error:  "line 1: record size exceeds the limit of 8",
+	},
+	{
+		Name:   "Fields Per Record",
+		Limits: text.Limits{MaxFieldsPerRecord: 2},
+		Input:  "a:1\tb:2\tc:3",
+		Error:  "line 1: number of fields in record exceeds the limit of 2",
+	},
+	{
+		Name:   "Records",
+		Limits: text.Limits{MaxRecords: 2},
+		Input:  "a:1\tb:1\na:2\tb:2\n\na:3\tb:3\n",
+		Error:  "line 4: number of records exceeds the limit of 2",
+	},
+}
+
+func TestReader_ReadAllWithLimits(t *testing.T) {
+	for _, v := range readAllWithLimitsTests {
+		r, _ := NewReader(strings.NewReader(v.Input), text.UTF8)
+		r.Limits = v.Limits
+
+		_, err := r.ReadAll()
+		if err != nil {
+			if v.Error == "" {
+				t.Errorf("%s: unexpected error %q", v.Name, err.Error())
+			} else if v.Error != err.Error() {
+				t.Errorf("%s: error %q, want error %q", v.Name, err.Error(), v.Error)
+			} else if !errors.As(err, new(*text.LimitError)) {
+				t.Errorf("%s: error type %T, want *text.LimitError", v.Name, err)
+			}
+			continue
+		}
+		if 0 < len(v.Error) {
+			t.Errorf("%s: no error, want error %q", v.Name, v.Error)
+		}
+	}
+}
+
+func TestNewIndexedReader(t *testing.T) {
+	input := text.UTF8BOM + "a:1\tb:日本\r\n\r\na:2\tb:\na:3\tb:語\na:4\tb:5"
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8M)
+	r.Index = text.NewRecordIndex(2)
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	expectEntries := []text.IndexEntry{
+		{Record: 0, Offset: 3, Line: 1},
+		{Record: 2, Offset: 26, Line: 4},
+	}
+	if !reflect.DeepEqual(r.Index.Entries, expectEntries) {
+		t.Errorf("entries = %v, want %v", r.Index.Entries, expectEntries)
+	}
+
+	for i := range records {
+		ir, err := NewIndexedReader(strings.NewReader(input), text.UTF8M, r.Index, i)
+		if err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+		result, err := ir.ReadAll()
+		if err != nil {
+			t.Errorf("record %d: unexpected error %q", i, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(result, records[i:]) {
+			t.Errorf("record %d: records = %q, want %q", i, result, records[i:])
+		}
+	}
+}
+
+func TestNewIndexedReader_CP930(t *testing.T) {
+	input := "\xd2\xf1z\x0eEbEf\x0f\x05\xd2\xf2z\xe5%\xd2\xf1z\x0eH\xe7\x0f\x05\xd2\xf2z\xe6%\xd2\xf1z\xe7\x05\xd2\xf2z\xe8%"
+
+	r, _ := NewReader(strings.NewReader(input), text.CP930)
+	r.Index = text.NewRecordIndex(1)
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+
+	expectEntries := []text.IndexEntry{
+		{Record: 0, Offset: 0, Line: 1},
+		{Record: 1, Offset: 15, Line: 2},
+		{Record: 2, Offset: 28, Line: 3},
+	}
+	if !reflect.DeepEqual(r.Index.Entries, expectEntries) {
+		t.Errorf("entries = %v, want %v", r.Index.Entries, expectEntries)
+	}
+
+	ir, err := NewIndexedReader(strings.NewReader(input), text.CP930, r.Index, 1)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	result, err := ir.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(result, records[1:]) {
+		t.Errorf("records = %q, want %q", result, records[1:])
+	}
+}
+
+func TestReader_ReadAllWithNullTokens(t *testing.T) {
+	input := "a:\\N\tb:1\na:2\tb:\n"
+	output := [][]text.RawText{
+		{nil, text.RawText("1")},
+		{text.RawText("2"), nil},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.NullTokens = text.NullTokens{"\\N"}
+
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	if !reflect.DeepEqual(records, output) {
+		t.Errorf("records = %q, want %q", records, output)
+	}
+}
+
+func TestReader_Source(t *testing.T) {
+	input := "a:1\tb:2\r\n\r\na:3\tb:日本"
+	output := []text.Source{
+		{Bytes: []byte("a:1\tb:2\r\n"), Text: "a:1\tb:2\r\n", Line: 1, Offset: 0},
+		{Bytes: []byte("a:3\tb:日本"), Text: "a:3\tb:日本", Line: 3, Offset: 11},
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	r.KeepSource = true
+
+	for i, expect := range output {
+		if _, err := r.Read(); err != nil {
+			t.Fatalf("unexpected error %q", err.Error())
+		}
+		if result := r.Source(); !reflect.DeepEqual(result, expect) {
+			t.Errorf("source %d = %#v, want %#v", i, result, expect)
+		}
+	}
+}
+
+func TestReader_ReadParseError(t *testing.T) {
+	input := "a:1\tb:2\na:3\tb\tc:4\n"
+	expect := &text.ParseError{
+		Kind:    text.SeparatorError,
+		Line:    2,
+		Column:  6,
+		Offset:  14,
+		Record:  1,
+		Field:   1,
+		Message: "missing field separator",
+	}
+
+	r, _ := NewReader(strings.NewReader(input), text.UTF8)
+	_, err := r.ReadAll()
+
+	var perr *text.ParseError
+	if !errors.As(err, &perr) {
+		t.Fatalf("error = %v, want *text.ParseError", err)
+	}
+	if !reflect.DeepEqual(perr, expect) {
+		t.Errorf("error = %#v, want %#v", perr, expect)
+	}
+}
@@ -11,6 +11,9 @@ import (
 )
 
 type Writer struct {
+	// NullToken is written for NULL values by WriteRawText.
+	NullToken string
+
 	header []string
 
 	writer    *bufio.Writer
@@ -39,6 +42,19 @@ func NewWriter(w io.Writer, header []string, lineBreak text.LineBreak, enc text.
 	}, nil
 }
 
+// WriteRawText writes the record in which nil values are NULL.
+func (e *Writer) WriteRawText(record []text.RawText) error {
+	values := make([]string, len(record))
+	for i, v := range record {
+		if v == nil {
+			values[i] = e.NullToken
+		} else {
+			values[i] = string(v)
+		}
+	}
+	return e.Write(values)
+}
+
 func (e *Writer) Write(record []string) error {
 	if len(record) != len(e.header) {
 		return errors.New("field length does not match")
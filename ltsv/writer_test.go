@@ -187,3 +187,20 @@ func TestWriter_Write(t *testing.T) {
 		}
 	}
 }
+
+func TestWriter_WriteRawText(t *testing.T) {
+	record := []text.RawText{nil, text.RawText("a"), {}}
+	expect := "a:\\N\tb:a\tc:"
+
+	buf := new(bytes.Buffer)
+	w, _ := NewWriter(buf, []string{"a", "b", "c"}, text.LF, text.UTF8)
+	w.NullToken = "\\N"
+	if err := w.WriteRawText(record); err != nil {
+		t.Fatalf("unexpected error %q", err.Error())
+	}
+	_ = w.Flush()
+
+	if buf.String() != expect {
+		t.Errorf("result = %q, want %q", buf.String(), expect)
+	}
+}
@@ -0,0 +1,204 @@
+package text
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ColumnType int
+
+const (
+	UnknownType ColumnType = iota
+	IntegerType
+	FloatType
+	BooleanType
+	DatetimeType
+	StringType
+)
+
+var ColumnTypeLiteral = map[ColumnType]string{
+	UnknownType:  "UNKNOWN",
+	IntegerType:  "INTEGER",
+	FloatType:    "FLOAT",
+	BooleanType:  "BOOLEAN",
+	DatetimeType: "DATETIME",
+	StringType:   "STRING",
+}
+
+func (t ColumnType) String() string {
+	return ColumnTypeLiteral[t]
+}
+
+// DefaultDatetimeFormats is the list of layouts tried in order to detect datetime values.
+var DefaultDatetimeFormats = []string{
+	"2006-01-02",
+	"2006/01/02",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	"15:04:05",
+}
+
+type ColumnSchema struct {
+	Type     ColumnType
+	Nullable bool
+	// Format is the datetime layout detected for DatetimeType columns.
+	Format string
+}
+
+type Schema []ColumnSchema
+
+const (
+	integerCandidate uint8 = 1 << iota
+	floatCandidate
+	booleanCandidate
+	datetimeCandidate
+
+	allCandidates = integerCandidate | floatCandidate | booleanCandidate | datetimeCandidate
+)
+
+type columnState struct {
+	candidates uint8
+	formats    []string
+	hasValue   bool
+	nullable   bool
+}
+
+// TypeInferrer infers the type of each column from records.
+//
+// A nil field is regarded as NULL. An empty non-nil field, which readers return
+// when WithoutNull is true, is regarded as NULL if EmptyAsNull is true,
+// otherwise it is regarded as an empty string.
+type TypeInferrer struct {
+	EmptyAsNull     bool
+	DatetimeFormats []string
+
+	columns []columnState
+	records int
+}
+
+func NewTypeInferrer() *TypeInferrer {
+	return &TypeInferrer{
+		EmptyAsNull:     true,
+		DatetimeFormats: DefaultDatetimeFormats,
+		columns:         make([]columnState, 0, 40),
+	}
+}
+
+func InferSchema(records [][]RawText) Schema {
+	inferrer := NewTypeInferrer()
+	for _, record := range records {
+		inferrer.Infer(record)
+	}
+	return inferrer.Schema()
+}
+
+func (t *TypeInferrer) Infer(record []RawText) {
+	for len(t.columns) < len(record) {
+		t.columns = append(t.columns, columnState{
+			candidates: allCandidates,
+			nullable:   0 < t.records,
+		})
+	}
+
+	for i := range t.columns {
+		if len(record) <= i {
+			t.columns[i].nullable = true
+			continue
+		}
+		t.inferValue(&t.columns[i], record[i])
+	}
+
+	t.records++
+}
+
+func (t *TypeInferrer) Schema() Schema {
+	schema := make(Schema, len(t.columns))
+	for i, c := range t.columns {
+		schema[i].Nullable = c.nullable
+
+		switch {
+		case !c.hasValue:
+			schema[i].Type = UnknownType
+			schema[i].Nullable = true
+		case c.candidates&integerCandidate != 0:
+			schema[i].Type = IntegerType
+		case c.candidates&floatCandidate != 0:
+			schema[i].Type = FloatType
+		case c.candidates&booleanCandidate != 0:
+			schema[i].Type = BooleanType
+		case c.candidates&datetimeCandidate != 0:
+			schema[i].Type = DatetimeType
+			schema[i].Format = c.formats[0]
+		default:
+			schema[i].Type = StringType
+		}
+	}
+	return schema
+}
+
+func (t *TypeInferrer) inferValue(c *columnState, v RawText) {
+	if v == nil || (len(v) < 1 && t.EmptyAsNull) {
+		c.nullable = true
+		return
+	}
+	c.hasValue = true
+
+	if c.candidates == 0 {
+		return
+	}
+	if len(v) < 1 {
+		c.candidates = 0
+		return
+	}
+
+	s := string(v)
+	if c.candidates&integerCandidate != 0 && !isInteger(s) {
+		c.candidates &^= integerCandidate
+	}
+	if c.candidates&floatCandidate != 0 && !isFloat(s) {
+		c.candidates &^= floatCandidate
+	}
+	if c.candidates&booleanCandidate != 0 && !isBoolean(s) {
+		c.candidates &^= booleanCandidate
+	}
+	if c.candidates&datetimeCandidate != 0 {
+		if c.formats == nil {
+			c.formats = t.DatetimeFormats
+		}
+		formats := make([]string, 0, len(c.formats))
+		for _, layout := range c.formats {
+			if _, err := time.Parse(layout, s); err == nil {
+				formats = append(formats, layout)
+			}
+		}
+		if len(formats) < 1 {
+			c.candidates &^= datetimeCandidate
+		}
+		c.formats = formats
+	}
+}
+
+func isInteger(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+func isFloat(s string) bool {
+	if strings.IndexAny(s, "0123456789") < 0 {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+func isBoolean(s string) bool {
+	return strings.EqualFold(s, "true") || strings.EqualFold(s, "false")
+}
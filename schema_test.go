@@ -0,0 +1,113 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+var inferSchemaTests = []struct {
+	Name        string
+	Records     [][]RawText
+	EmptyAsNull bool
+	Expect      Schema
+}{
+	{
+		Name: "Basic Types",
+		Records: [][]RawText{
+			{RawText("1"), RawText("1.5"), RawText("true"), RawText("2012-02-03"), RawText("abc")},
+			{RawText("-20"), RawText("2"), RawText("FALSE"), RawText("2012-02-04"), RawText("123")},
+			{RawText("+3"), RawText("1e3"), RawText("True"), RawText("2012-12-31"), RawText("true")},
+		},
+		EmptyAsNull: true,
+		Expect: Schema{
+			{Type: IntegerType},
+			{Type: FloatType},
+			{Type: BooleanType},
+			{Type: DatetimeType, Format: "2006-01-02"},
+			{Type: StringType},
+		},
+	},
+	{
+		Name: "Datetime Formats",
+		Records: [][]RawText{
+			{RawText("2012-02-03 09:18:15"), RawText("2012-02-03T09:18:15+09:00"), RawText("2012-02-03")},
+			{RawText("2012-02-03 09:18:15.123"), RawText("2012-02-03T09:18:15.123456Z"), RawText("2012/02/03")},
+		},
+		EmptyAsNull: true,
+		Expect: Schema{
+			{Type: DatetimeType, Format: "2006-01-02 15:04:05"},
+			{Type: DatetimeType, Format: "2006-01-02T15:04:05Z07:00"},
+			{Type: StringType},
+		},
+	},
+	{
+		Name: "Nulls and Empty Values",
+		Records: [][]RawText{
+			{RawText("1"), nil, RawText("a"), nil},
+			{RawText{}, RawText("2"), RawText{}, nil},
+			{RawText("3"), RawText("4")},
+		},
+		EmptyAsNull: true,
+		Expect: Schema{
+			{Type: IntegerType, Nullable: true},
+			{Type: IntegerType, Nullable: true},
+			{Type: StringType, Nullable: true},
+			{Type: UnknownType, Nullable: true},
+		},
+	},
+	{
+		Name: "Empty Values As Strings",
+		Records: [][]RawText{
+			{RawText("1"), nil},
+			{RawText{}, RawText("2")},
+		},
+		EmptyAsNull: false,
+		Expect: Schema{
+			{Type: StringType},
+			{Type: IntegerType, Nullable: true},
+		},
+	},
+	{
+		Name: "Uneven Fields",
+		Records: [][]RawText{
+			{RawText("1")},
+			{RawText("2"), RawText("nan")},
+		},
+		EmptyAsNull: true,
+		Expect: Schema{
+			{Type: IntegerType},
+			{Type: StringType, Nullable: true},
+		},
+	},
+}
+
+func TestTypeInferrer_Schema(t *testing.T) {
+	for _, v := range inferSchemaTests {
+		inferrer := NewTypeInferrer()
+		inferrer.EmptyAsNull = v.EmptyAsNull
+		for _, record := range v.Records {
+			inferrer.Infer(record)
+		}
+
+		result := inferrer.Schema()
+		if !reflect.DeepEqual(result, v.Expect) {
+			t.Errorf("%s: schema = %v, want %v", v.Name, result, v.Expect)
+		}
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	records := [][]RawText{
+		{RawText("1"), RawText("a")},
+		{nil, RawText("b")},
+	}
+	expect := Schema{
+		{Type: IntegerType, Nullable: true},
+		{Type: StringType},
+	}
+
+	result := InferSchema(records)
+	if !reflect.DeepEqual(result, expect) {
+		t.Errorf("schema = %v, want %v", result, expect)
+	}
+}
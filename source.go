@@ -0,0 +1,29 @@
+package text
+
+// Source is the original text of a record.
+type Source struct {
+	// Bytes is the undecoded text including the terminating line break.
+	Bytes []byte
+	// Text is the decoded text of Bytes.
+	Text string
+	// Line is the line number where the record starts.
+	Line int
+	// Offset is the byte offset of the record in the source.
+	Offset int64
+}
+
+func NewSource(b []byte, enc Encoding, line int, offset int64) Source {
+	if e, _, err := SeekEncoding(enc); err == nil {
+		enc = e
+	}
+
+	s := Source{
+		Bytes:  b,
+		Line:   line,
+		Offset: offset,
+	}
+	if decoded, err := Decode(b, enc); err == nil {
+		s.Text = string(decoded)
+	}
+	return s
+}
@@ -41,11 +41,14 @@ func RuneWidth(r rune, eastAsianEncoding bool, countDiacriticalSign bool, countF
 }
 
 // RuneByteSize calculates byte size of a character.
+// In CP930 and CP939, the size does not include shift codes.
 func RuneByteSize(r rune, encoding Encoding) int {
 	if encoding == SJIS {
 		return sjisRuneByteSize(r)
 	} else if isUTF16Encoding(encoding) {
 		return utf16RuneByteSize(r)
+	} else if IsEBCDICEncoding(encoding) {
+		return ebcdicRuneByteSize(r, encoding)
 	}
 	return len(string(r))
 }
@@ -69,7 +72,12 @@ func utf16RuneByteSize(r rune) int {
 }
 
 // ByteSize calculates byte size of a string.
+// In CP930 and CP939, the size includes a shift-out and a shift-in code for each run of double-byte characters.
 func ByteSize(s string, encoding Encoding) int {
+	if IsShiftEncoding(encoding) {
+		return ebcdicByteSize(s, encoding)
+	}
+
 	size := 0
 	for _, c := range s {
 		size = size + RuneByteSize(c, encoding)
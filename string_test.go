@@ -120,6 +120,26 @@ var runeByteSizeTests = []struct {
 		Encoding: SJIS,
 		Expect:   1,
 	},
+	{
+		Rune:     '日',
+		Encoding: CP930,
+		Expect:   2,
+	},
+	{
+		Rune:     'ｱ',
+		Encoding: CP930,
+		Expect:   1,
+	},
+	{
+		Rune:     'ア',
+		Encoding: CP939,
+		Expect:   2,
+	},
+	{
+		Rune:     'a',
+		Encoding: CP037,
+		Expect:   1,
+	},
 }
 
 func TestRuneByteSize(t *testing.T) {
@@ -151,6 +171,21 @@ var byteSizeTests = []struct {
 		Encoding: UTF16,
 		Expect:   10,
 	},
+	{
+		String:   "AB日本c",
+		Encoding: CP939,
+		Expect:   9,
+	},
+	{
+		String:   "日本A語",
+		Encoding: CP930,
+		Expect:   11,
+	},
+	{
+		String:   "abc",
+		Encoding: CP500,
+		Expect:   3,
+	},
 }
 
 func TestByteSize(t *testing.T) {
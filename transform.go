@@ -43,7 +43,7 @@ func DetectEncoding(r io.ReadSeeker) (detected Encoding, err error) {
 
 func DetectInSpecifiedEncoding(r io.ReadSeeker, enc Encoding) (detected Encoding, err error) {
 	switch enc {
-	case UTF8M, UTF16BEM, UTF16LEM, UTF16BE, UTF16LE, SJIS:
+	case UTF8M, UTF16BEM, UTF16LEM, UTF16BE, UTF16LE, SJIS, CP037, CP500, CP930, CP939:
 		return enc, nil
 	}
 
@@ -283,6 +283,8 @@ func GetTransformEncoder(r io.Reader, enc Encoding) (io.Reader, error) {
 		return transform.NewReader(r, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewEncoder()), nil
 	case SJIS:
 		return transform.NewReader(r, japanese.ShiftJIS.NewEncoder()), nil
+	case CP037, CP500, CP930, CP939:
+		return transform.NewReader(r, getEBCDICEncoding(enc).NewEncoder()), nil
 	default:
 		return nil, ErrInvalidEncoding
 	}
@@ -307,6 +309,8 @@ func GetTransformDecoder(r io.Reader, enc Encoding) (io.Reader, error) {
 		return transform.NewReader(r, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewDecoder()), nil
 	case SJIS:
 		return transform.NewReader(r, japanese.ShiftJIS.NewDecoder()), nil
+	case CP037, CP500, CP930, CP939:
+		return transform.NewReader(r, getEBCDICEncoding(enc).NewDecoder()), nil
 	default:
 		return nil, ErrInvalidEncoding
 	}
@@ -331,6 +335,8 @@ func GetTransformWriter(w io.Writer, enc Encoding) (io.Writer, error) {
 		return transform.NewWriter(w, unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM).NewEncoder()), nil
 	case SJIS:
 		return transform.NewWriter(w, japanese.ShiftJIS.NewEncoder()), nil
+	case CP037, CP500, CP930, CP939:
+		return transform.NewWriter(w, getEBCDICEncoding(enc).NewEncoder()), nil
 	default:
 		return nil, ErrInvalidEncoding
 	}
@@ -331,6 +331,14 @@ var encodeTests = []struct {
 		Encoding: SJIS,
 		Expect:   []byte{0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea},
 	},
+	{
+		Encoding: CP930,
+		Expect:   []byte{0x0e, 0x45, 0x62, 0x45, 0x66, 0x48, 0xe7, 0x0f},
+	},
+	{
+		Encoding: CP037,
+		Error:    "'日' cannot be encoded in CP037",
+	},
 	{
 		Encoding: AUTO,
 		Error:    "invalid character encoding",
@@ -405,6 +413,10 @@ var decodeTests = []struct {
 		Encoding: SJIS,
 		Source:   []byte{0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea},
 	},
+	{
+		Encoding: CP939,
+		Source:   []byte{0x0e, 0x45, 0x62, 0x45, 0x66, 0x48, 0xe7, 0x0f},
+	},
 	{
 		Encoding: AUTO,
 		Error:    "invalid character encoding",
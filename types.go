@@ -19,6 +19,10 @@ const (
 	UTF16BE
 	UTF16LE
 	SJIS
+	CP037
+	CP500
+	CP930
+	CP939
 )
 
 var EncodingLiteral = map[Encoding]string{
@@ -31,6 +35,10 @@ var EncodingLiteral = map[Encoding]string{
 	UTF16BE:  "UTF16BE",
 	UTF16LE:  "UTF16LE",
 	SJIS:     "SJIS",
+	CP037:    "CP037",
+	CP500:    "CP500",
+	CP930:    "CP930",
+	CP939:    "CP939",
 }
 
 func (e Encoding) String() string {
@@ -70,6 +78,18 @@ const (
 
 type RawText []byte
 
+// NullTokens is a list of field values regarded as NULL, such as "\\N", "NULL" or "NA".
+type NullTokens []string
+
+func (t NullTokens) Match(b []byte) bool {
+	for _, s := range t {
+		if string(b) == s {
+			return true
+		}
+	}
+	return false
+}
+
 func ParseEncoding(s string) (Encoding, error) {
 	var encoding Encoding
 	switch strings.ToUpper(s) {
@@ -91,6 +111,14 @@ func ParseEncoding(s string) (Encoding, error) {
 		encoding = UTF16LE
 	case "SJIS":
 		encoding = SJIS
+	case "CP037":
+		encoding = CP037
+	case "CP500":
+		encoding = CP500
+	case "CP930":
+		encoding = CP930
+	case "CP939":
+		encoding = CP939
 	default:
 		return encoding, errors.New(fmt.Sprintf("%q cannot convert to Encoding", s))
 	}
@@ -42,6 +42,10 @@ var parseEncodingTests = []struct {
 		Input:  "sjis",
 		Expect: SJIS,
 	},
+	{
+		Input:  "cp930",
+		Expect: CP930,
+	},
 	{
 		Input:  "auto",
 		Expect: AUTO,
@@ -116,3 +120,21 @@ func TestParseLineBreak(t *testing.T) {
 		}
 	}
 }
+
+func TestNullTokens_Match(t *testing.T) {
+	tokens := NullTokens{"\\N", "NULL"}
+
+	for _, v := range []struct {
+		Input  string
+		Expect bool
+	}{
+		{Input: "\\N", Expect: true},
+		{Input: "NULL", Expect: true},
+		{Input: "null", Expect: false},
+		{Input: "", Expect: false},
+	} {
+		if result := tokens.Match([]byte(v.Input)); result != v.Expect {
+			t.Errorf("match = %t, want %t for %q", result, v.Expect, v.Input)
+		}
+	}
+}